@@ -0,0 +1,74 @@
+package main
+
+import "sync/atomic"
+
+// ABTestingCache routes the fraction experimentFraction of keys to cache B
+// and all others to cache A, so two cache strategies can be compared on the
+// same traffic. Keys are routed by their hash, every key always goes to the
+// same cache.
+type ABTestingCache[K comparable, T any] struct {
+	a, b               abTestingArm[K, T]
+	experimentFraction float64
+}
+
+type abTestingArm[K comparable, T any] struct {
+	cache  Cache[K, T]
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewCacheWithABTesting[K comparable, T any](experimentFraction float64, a, b Cache[K, T]) *ABTestingCache[K, T] {
+	c := &ABTestingCache[K, T]{experimentFraction: experimentFraction}
+	c.a.cache = a
+	c.b.cache = b
+	return c
+}
+
+func (c *ABTestingCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	arm := c.route(key)
+	value, hit := loadOrStoreHit(arm.cache, key, read)
+	if hit {
+		arm.hits.Add(1)
+	} else {
+		arm.misses.Add(1)
+	}
+	return value
+}
+
+func (c *ABTestingCache[K, T]) Peek(key K) (T, bool) {
+	return c.route(key).cache.Peek(key)
+}
+
+func (c *ABTestingCache[K, T]) SetDefault(key K, value T) bool {
+	return c.route(key).cache.SetDefault(key, value)
+}
+
+func (c *ABTestingCache[K, T]) Delete(key K) {
+	c.route(key).cache.Delete(key)
+}
+
+// CompareStats returns the stats of both caches, their hits and misses are
+// those of LoadOrStore calls routed to them.
+func (c *ABTestingCache[K, T]) CompareStats() (aStats, bStats Stats) {
+	return c.a.stats(), c.b.stats()
+}
+
+// route returns the arm of the key, keys whose hash falls into the lowest
+// experimentFraction of hashes go to B.
+func (c *ABTestingCache[K, T]) route(key K) *abTestingArm[K, T] {
+	hash, _ := keyToHash(key)
+	if float64(mix64(hash)>>11)/(1<<53) < c.experimentFraction {
+		return &c.b
+	}
+	return &c.a
+}
+
+func (a *abTestingArm[K, T]) stats() Stats {
+	var stats Stats
+	if cache, ok := a.cache.(interface{ Stats() Stats }); ok {
+		stats = cache.Stats()
+	}
+	stats.Hits = a.hits.Load()
+	stats.Misses = a.misses.Load()
+	return stats
+}
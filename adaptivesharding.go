@@ -0,0 +1,267 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// shardCheckInterval is how often AdaptiveShardedCache checks the
+	// contention of its shards.
+	shardCheckInterval = time.Second
+	// shardSplitContention is the share of contended accesses from which a
+	// shard is split.
+	shardSplitContention = 0.05
+	// shardMinAccesses is the number of accesses per check below which a
+	// shard counts as underutilized and may be merged with its buddy.
+	shardMinAccesses = 100
+	maxShardDepth    = 10
+)
+
+// AdaptiveShardedCache spreads its entries over shards with their own lock
+// and adjusts the number of shards to the contention on them with
+// extendible hashing: a shard whose lock was found held on too many accesses
+// is split into two by the next bit of the key hashes, two buddy shards
+// accessed rarely without contention are merged. Entries expire after the
+// default TTL like in the other caches.
+type AdaptiveShardedCache[K comparable, T any] struct {
+	// mu guards the directory, splits and merges lock it for writing
+	mu sync.RWMutex
+	// directory maps the low bits of key hashes to shards, shards of local
+	// depth d appear at every index sharing their low d bits
+	directory []*adaptiveShard[K, T]
+	depth     int
+
+	locks sync.Map
+	stop  chan struct{}
+}
+
+type adaptiveShard[K comparable, T any] struct {
+	mu        sync.Mutex
+	entries   map[K]*itemValue[T]
+	depth     int
+	accesses  atomic.Uint64
+	contended atomic.Uint64
+}
+
+func newAdaptiveShard[K comparable, T any](depth int) *adaptiveShard[K, T] {
+	return &adaptiveShard[K, T]{entries: make(map[K]*itemValue[T]), depth: depth}
+}
+
+// lock locks the shard counting whether the lock was held already.
+func (s *adaptiveShard[K, T]) lock() {
+	s.accesses.Add(1)
+	if !s.mu.TryLock() {
+		s.contended.Add(1)
+		s.mu.Lock()
+	}
+}
+
+func NewCacheWithAdaptiveSharding[K comparable, T any]() *AdaptiveShardedCache[K, T] {
+	c := &AdaptiveShardedCache[K, T]{
+		directory: []*adaptiveShard[K, T]{newAdaptiveShard[K, T](0)},
+		stop:      make(chan struct{}),
+	}
+	go c.adapt()
+	return c
+}
+
+func (c *AdaptiveShardedCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock := anyLock.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	value := read()
+	c.SetDefault(key, value)
+	return value
+}
+
+func (c *AdaptiveShardedCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := c.shard(key)
+	s.lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.entries[key]
+	if !ok || !time.Now().Before(item.expiresAt) {
+		delete(s.entries, key)
+		var zero T
+		return zero, false
+	}
+	return item.value, true
+}
+
+func (c *AdaptiveShardedCache[K, T]) SetDefault(key K, value T) bool {
+	now := time.Now()
+	item := &itemValue[T]{value: value, computedAt: now, expiresAt: now.Add(ttl)}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := c.shard(key)
+	s.lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = item
+	return true
+}
+
+func (c *AdaptiveShardedCache[K, T]) Delete(key K) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := c.shard(key)
+	s.lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+// Shards returns the current number of shards.
+func (c *AdaptiveShardedCache[K, T]) Shards() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.shards())
+}
+
+func (c *AdaptiveShardedCache[K, T]) Close() {
+	close(c.stop)
+}
+
+func (c *AdaptiveShardedCache[K, T]) shard(key K) *adaptiveShard[K, T] {
+	return c.directory[shardHash(key)&uint64(len(c.directory)-1)]
+}
+
+// shards returns every shard once with the index it first appears at.
+func (c *AdaptiveShardedCache[K, T]) shards() map[*adaptiveShard[K, T]]int {
+	shards := make(map[*adaptiveShard[K, T]]int)
+	for i, s := range c.directory {
+		if _, ok := shards[s]; !ok {
+			shards[s] = i
+		}
+	}
+	return shards
+}
+
+func (c *AdaptiveShardedCache[K, T]) adapt() {
+	ticker := time.NewTicker(shardCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		}
+		c.rebalance()
+	}
+}
+
+// rebalance splits the contended shards and merges underutilized buddies.
+func (c *AdaptiveShardedCache[K, T]) rebalance() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	underutilized := make(map[*adaptiveShard[K, T]]bool)
+	for s, index := range c.shards() {
+		accesses, contended := s.accesses.Swap(0), s.contended.Swap(0)
+		switch {
+		case accesses > 0 && float64(contended)/float64(accesses) > shardSplitContention:
+			if s.depth < maxShardDepth {
+				c.split(s, index)
+			}
+		case accesses < shardMinAccesses && contended == 0:
+			underutilized[s] = true
+		}
+	}
+
+	for s, index := range c.shards() {
+		if !underutilized[s] || s.depth == 0 {
+			continue
+		}
+		buddy := c.directory[index^(1<<(s.depth-1))]
+		if buddy != s && buddy.depth == s.depth && underutilized[buddy] {
+			delete(underutilized, s)
+			delete(underutilized, buddy)
+			c.merge(s, buddy, index)
+		}
+	}
+
+	// halve the directory while no shard needs its highest bit
+	for c.depth > 0 {
+		for _, s := range c.directory {
+			if s.depth == c.depth {
+				return
+			}
+		}
+		c.depth--
+		c.directory = c.directory[:len(c.directory)/2]
+	}
+}
+
+// split replaces the shard by two shards telling its keys apart by the next
+// bit of their hashes.
+func (c *AdaptiveShardedCache[K, T]) split(s *adaptiveShard[K, T], index int) {
+	if s.depth == c.depth {
+		c.directory = append(c.directory, c.directory...)
+		c.depth++
+	}
+
+	bit := uint64(1) << s.depth
+	halves := [2]*adaptiveShard[K, T]{newAdaptiveShard[K, T](s.depth + 1), newAdaptiveShard[K, T](s.depth + 1)}
+	for key, item := range s.entries {
+		if shardHash(key)&bit == 0 {
+			halves[0].entries[key] = item
+		} else {
+			halves[1].entries[key] = item
+		}
+	}
+
+	low := uint64(index) & (bit - 1)
+	for i := range c.directory {
+		if uint64(i)&(bit-1) != low {
+			continue
+		}
+		if uint64(i)&bit == 0 {
+			c.directory[i] = halves[0]
+		} else {
+			c.directory[i] = halves[1]
+		}
+	}
+}
+
+// merge replaces the buddy shards by one shard of the next lower depth.
+func (c *AdaptiveShardedCache[K, T]) merge(s, buddy *adaptiveShard[K, T], index int) {
+	merged := newAdaptiveShard[K, T](s.depth - 1)
+	for key, item := range s.entries {
+		merged.entries[key] = item
+	}
+	for key, item := range buddy.entries {
+		merged.entries[key] = item
+	}
+
+	mask := uint64(1)<<merged.depth - 1
+	for i := range c.directory {
+		if uint64(i)&mask == uint64(index)&mask {
+			c.directory[i] = merged
+		}
+	}
+}
+
+func shardHash(key interface{}) uint64 {
+	hash, _ := keyToHash(key)
+	return mix64(hash)
+}
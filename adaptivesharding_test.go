@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// contend accesses the shard while its lock is held, as a goroutine finding
+// the lock taken would.
+func contend[K comparable, T any](s *adaptiveShard[K, T]) {
+	s.mu.Lock()
+	contended := s.contended.Load()
+	done := make(chan struct{})
+	go func() {
+		s.lock()
+		s.mu.Unlock()
+		close(done)
+	}()
+	for s.contended.Load() == contended {
+		time.Sleep(10 * time.Microsecond)
+	}
+	s.mu.Unlock()
+	<-done
+}
+
+func TestAdaptiveShardingSplitsAndMerges(t *testing.T) {
+	c := NewCacheWithAdaptiveSharding[string, int]()
+	// rebalance by hand instead of every second
+	c.Close()
+
+	const n = 1000
+	for i := 0; i < n; i++ {
+		c.SetDefault(strconv.Itoa(i), i)
+	}
+	// the stores were not contended
+	c.rebalance()
+	if got := c.Shards(); got != 1 {
+		t.Fatalf("got %d shards after uncontended stores", got)
+	}
+	// every shard is found locked on 20 of its accesses
+	for want := 2; want <= 8; want *= 2 {
+		for s := range c.shards() {
+			for i := 0; i < 20; i++ {
+				contend(s)
+			}
+		}
+		c.rebalance()
+		if got := c.Shards(); got != want {
+			t.Fatalf("got %d shards after contention, want %d", got, want)
+		}
+	}
+	// idle shards are merged with their buddies, one level per check
+	for want := 4; want >= 1; want /= 2 {
+		c.rebalance()
+		if got := c.Shards(); got != want {
+			t.Fatalf("got %d shards while idle, want %d", got, want)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if value, ok := c.Peek(strconv.Itoa(i)); !ok || value != i {
+			t.Fatalf("key %d lost by splits and merges", i)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const adaptiveTTLWindow = 5 * time.Minute
+
+func WithTargetHitRate[K comparable, T any](rate float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.targetHitRate = rate
+	}
+}
+
+func WithMinTTL[K comparable, T any](ttl time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.minTTL = ttl
+	}
+}
+
+func WithMaxTTL[K comparable, T any](ttl time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.maxTTL = ttl
+	}
+}
+
+// AdaptiveTTLCache adjusts the TTL of new entries by 10% every five minutes
+// to move the hit rate of the window towards the target hit rate: the TTL
+// grows while the hit rate is below the target and shrinks while it is above
+// it. The TTL stays between WithMinTTL and WithMaxTTL.
+type AdaptiveTTLCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	ttl    atomic.Int64
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	stop   chan struct{}
+}
+
+func NewCacheWithHitRateAdaptiveTTL[K comparable, T any](opts ...Option[K, T]) (*AdaptiveTTLCache[K, T], error) {
+	c := &AdaptiveTTLCache[K, T]{stop: make(chan struct{})}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.ttlOf = func(*entry[K, T]) time.Duration {
+			return time.Duration(c.ttl.Load())
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	c.ttl.Store(int64(cache.defaultTTL))
+
+	go c.adjust()
+	return c, nil
+}
+
+func (c *AdaptiveTTLCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *AdaptiveTTLCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, hit, err := c.loadOrStoreE(key, read)
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, err
+}
+
+// TTL returns the TTL currently used for new entries.
+func (c *AdaptiveTTLCache[K, T]) TTL() time.Duration {
+	return time.Duration(c.ttl.Load())
+}
+
+func (c *AdaptiveTTLCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *AdaptiveTTLCache[K, T]) adjust() {
+	ticker := time.NewTicker(adaptiveTTLWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		}
+
+		hits, misses := c.hits.Swap(0), c.misses.Swap(0)
+		if hits+misses == 0 {
+			continue
+		}
+		ttl := c.TTL()
+		if float64(hits)/float64(hits+misses) < c.targetHitRate {
+			ttl += ttl / 10
+		} else {
+			ttl -= ttl / 10
+		}
+		if c.minTTL > 0 && ttl < c.minTTL {
+			ttl = c.minTTL
+		}
+		if c.maxTTL > 0 && ttl > c.maxTTL {
+			ttl = c.maxTTL
+		}
+		c.ttl.Store(int64(ttl))
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "sync/atomic"
+
+// ApproximateSizeCache counts the cached entries without keeping a set of
+// their keys. Every stored entry is counted and every removed one, including
+// entries replaced by an update or rejected by ristretto, is subtracted, so
+// the count only lags behind while sets are buffered.
+type ApproximateSizeCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	entries atomic.Int64
+}
+
+func NewCacheWithApproximateSize[K comparable, T any](opts ...Option[K, T]) (*ApproximateSizeCache[K, T], error) {
+	c := &ApproximateSizeCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(*entry[K, T]) {
+			c.entries.Add(1)
+		}
+		o.onExit = func(*entry[K, T]) {
+			c.entries.Add(-1)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// ApproximateLen returns the number of cached entries, including expired
+// ones ristretto has not removed yet.
+func (c *ApproximateSizeCache[K, T]) ApproximateLen() int64 {
+	return max(c.entries.Load(), 0)
+}
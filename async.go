@@ -0,0 +1,65 @@
+package main
+
+import "sync"
+
+// Result is the outcome of an asynchronous load.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// AsyncCache loads values in the background and delivers them on channels.
+type AsyncCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu sync.Mutex
+	// pending holds the channels waiting for each key being loaded
+	pending map[K][]chan Result[T]
+}
+
+func NewCacheWithPromiseBasedAPI[K comparable, T any](opts ...Option[K, T]) (*AsyncCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AsyncCache[K, T]{
+		ristrettoCache: cache,
+		pending:        make(map[K][]chan Result[T]),
+	}, nil
+}
+
+// LoadOrStoreAsync returns a channel receiving the result and closed
+// afterwards. Callers for a key already being loaded get their own channel
+// receiving the result of the same load, their readers are not called.
+func (c *AsyncCache[K, T]) LoadOrStoreAsync(key K, read reader[T]) <-chan Result[T] {
+	result := make(chan Result[T], 1)
+	if value, ok := c.Peek(key); ok {
+		result <- Result[T]{Value: value}
+		close(result)
+		return result
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiting, loading := c.pending[key]
+	c.pending[key] = append(waiting, result)
+	if !loading {
+		go c.load(key, read)
+	}
+	return result
+}
+
+func (c *AsyncCache[K, T]) load(key K, read reader[T]) {
+	value, err := c.LoadOrStoreE(key, read.withError())
+
+	c.mu.Lock()
+	waiting := c.pending[key]
+	delete(c.pending, key)
+	c.mu.Unlock()
+
+	for _, result := range waiting {
+		result <- Result[T]{Value: value, Err: err}
+		close(result)
+	}
+}
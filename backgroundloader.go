@@ -0,0 +1,49 @@
+package main
+
+import "sync"
+
+// BackgroundLoaderCache loads keys missed by Peek in the background, so a
+// later LoadOrStore finds them cached. Background loads go through
+// LoadOrStoreE, they are limited by WithMaxConcurrentReads and coalesce with
+// concurrent loads of the key. Failed background loads are not retried until
+// the next missed Peek.
+type BackgroundLoaderCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	loader func(K) (T, error)
+	// loading holds the keys with a background load in progress
+	loading sync.Map
+}
+
+func NewCacheWithBackgroundLoader[K comparable, T any](loader func(K) (T, error), opts ...Option[K, T]) (*BackgroundLoaderCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &BackgroundLoaderCache[K, T]{ristrettoCache: cache, loader: loader}, nil
+}
+
+// Peek returns the cached value, on a miss it starts loading the key.
+func (c *BackgroundLoaderCache[K, T]) Peek(key K) (T, bool) {
+	value, ok := c.ristrettoCache.Peek(key)
+	if !ok {
+		c.load(key)
+	}
+	return value, ok
+}
+
+// Load returns the cached value, reading it with the loader on a miss.
+func (c *BackgroundLoaderCache[K, T]) Load(key K) (T, error) {
+	return c.LoadOrStoreE(key, func() (T, error) {
+		return c.loader(key)
+	})
+}
+
+func (c *BackgroundLoaderCache[K, T]) load(key K) {
+	if _, loading := c.loading.LoadOrStore(key, struct{}{}); loading {
+		return
+	}
+	go func() {
+		defer c.loading.Delete(key)
+		c.Load(key)
+	}()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// WithInitialRetryInterval sets how long ExponentialBackoffCache waits
+// before reading a key again after its first failed read, 1s by default.
+func WithInitialRetryInterval[K comparable, T any](d time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.retryInterval = d
+	}
+}
+
+// WithMaxRetryInterval caps the interval ExponentialBackoffCache waits
+// between reads of a failing key, 1m by default.
+func WithMaxRetryInterval[K comparable, T any](d time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.maxRetry = d
+	}
+}
+
+// ExponentialBackoffCache does not retry reading a key for the retry
+// interval after its reader failed, callers get ErrCoolingDown instead. The
+// interval doubles with every failed read up to the maximum retry interval
+// and is reset by a successful read.
+type ExponentialBackoffCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// backoffs holds a keyBackoff per failing key
+	backoffs sync.Map
+}
+
+type keyBackoff struct {
+	interval time.Duration
+	until    time.Time
+}
+
+func NewCacheWithExponentialBackoffRefresh[K comparable, T any](opts ...Option[K, T]) (*ExponentialBackoffCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{
+		WithInitialRetryInterval[K, T](time.Second),
+		WithMaxRetryInterval[K, T](time.Minute),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &ExponentialBackoffCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *ExponentialBackoffCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *ExponentialBackoffCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		prev, failing := c.backoffs.Load(key)
+		if failing && time.Now().Before(prev.(keyBackoff).until) {
+			var zero T
+			return zero, ErrCoolingDown
+		}
+
+		value, err := read()
+		if err != nil {
+			interval := c.retryInterval
+			if failing {
+				interval = min(2*prev.(keyBackoff).interval, c.maxRetry)
+			}
+			c.backoffs.Store(key, keyBackoff{interval: interval, until: time.Now().Add(interval)})
+			return value, err
+		}
+		c.backoffs.Delete(key)
+		return value, nil
+	}, read)
+	return value, err
+}
+
+// RetryInterval returns the interval the key is not read for after its last
+// failed read, or 0 if its last read succeeded.
+func (c *ExponentialBackoffCache[K, T]) RetryInterval(key K) time.Duration {
+	if b, ok := c.backoffs.Load(key); ok {
+		return b.(keyBackoff).interval
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffUpToMax(t *testing.T) {
+	c, err := NewCacheWithExponentialBackoffRefresh(
+		WithInitialRetryInterval[string, int](10*time.Millisecond),
+		WithMaxRetryInterval[string, int](80*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	errDown := errors.New("backend down")
+	reads := 0
+	failing := func() (int, error) {
+		reads++
+		return 0, errDown
+	}
+	for _, want := range []time.Duration{10, 20, 40, 80, 80} {
+		want *= time.Millisecond
+		if _, err := c.LoadOrStoreE("k", failing); !errors.Is(err, errDown) {
+			t.Fatalf("got %v, want the reader's error", err)
+		}
+		if got := c.RetryInterval("k"); got != want {
+			t.Errorf("retry interval %s, want %s", got, want)
+		}
+		// the key is not read again within the interval
+		before := reads
+		if _, err := c.LoadOrStoreE("k", failing); !errors.Is(err, ErrCoolingDown) || reads != before {
+			t.Errorf("got %v within the retry interval, want %v", err, ErrCoolingDown)
+		}
+		time.Sleep(want)
+	}
+
+	if value, err := c.LoadOrStoreE("k", func() (int, error) { return 1, nil }); err != nil || value != 1 {
+		t.Fatalf("got %d, %v after the backend recovered", value, err)
+	}
+	if got := c.RetryInterval("k"); got != 0 {
+		t.Errorf("retry interval %s after a successful read, want it reset", got)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// BandwidthLimitedCache limits the bytes of values returned by LoadOrStore
+// per second, callers block while the budget is exhausted. Peek is not
+// limited.
+type BandwidthLimitedCache[K comparable] struct {
+	*ristrettoCache[K, []byte]
+	limiter *rate.Limiter
+}
+
+func NewCacheWithEgressBandwidthLimit[K comparable](bytesPerSec int64, opts ...Option[K, []byte]) (*BandwidthLimitedCache[K], error) {
+	if bytesPerSec <= 0 {
+		return nil, fmt.Errorf("bandwidth limit must be positive, got %d bytes per second", bytesPerSec)
+	}
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	// the burst is a second's worth of bytes, as far as int can hold it
+	burst := int(min(bytesPerSec, math.MaxInt))
+	return &BandwidthLimitedCache[K]{
+		ristrettoCache: cache,
+		limiter:        rate.NewLimiter(rate.Limit(bytesPerSec), burst),
+	}, nil
+}
+
+// LoadOrStoreE returns ctx.Err() if ctx is done while waiting for the
+// bandwidth budget.
+func (c *BandwidthLimitedCache[K]) LoadOrStoreE(ctx context.Context, key K, read readerE[[]byte]) ([]byte, error) {
+	value, err := c.ristrettoCache.LoadOrStoreE(key, read)
+	if err != nil {
+		return value, err
+	}
+	if err := c.wait(ctx, len(value)); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (c *BandwidthLimitedCache[K]) LoadOrStore(ctx context.Context, key K, read reader[[]byte]) ([]byte, error) {
+	return c.LoadOrStoreE(ctx, key, read.withError())
+}
+
+// wait takes n bytes from the budget, values larger than a second's worth
+// of bytes are taken in parts.
+func (c *BandwidthLimitedCache[K]) wait(ctx context.Context, n int) error {
+	for n > 0 {
+		part := min(n, c.limiter.Burst())
+		if err := c.limiter.WaitN(ctx, part); err != nil {
+			return err
+		}
+		n -= part
+	}
+	return nil
+}
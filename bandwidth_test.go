@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimitRejectsNonPositiveRates(t *testing.T) {
+	for _, bytesPerSec := range []int64{0, -1} {
+		if _, err := NewCacheWithEgressBandwidthLimit[string](bytesPerSec); err == nil {
+			t.Errorf("rate %d accepted", bytesPerSec)
+		}
+	}
+}
+
+func TestBandwidthLimitBlocksOnceTheBudgetIsExhausted(t *testing.T) {
+	c, err := NewCacheWithEgressBandwidthLimit[string](100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	read := func() []byte { return make([]byte, 100) }
+	if _, err := c.LoadOrStore(context.Background(), "k", read); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// the next 100 bytes are only available after a second
+	if _, err := c.LoadOrStore(ctx, "k", read); err == nil {
+		t.Error("budget not limited")
+	}
+}
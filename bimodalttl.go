@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BimodalTTLCache stores entries with the cold TTL and renews them on every
+// hit, with the hot TTL once their key was hit hotThreshold times. Hit counts
+// are reset when the entry expires, is evicted or deleted.
+type BimodalTTLCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	hotTTL       time.Duration
+	coldTTL      time.Duration
+	hotThreshold int64
+	// counts holds an *atomic.Int64 per key
+	counts sync.Map
+}
+
+func NewCacheWithBimodalTTL[K comparable, T any](hotTTL, coldTTL time.Duration, hotThreshold int, opts ...Option[K, T]) (*BimodalTTLCache[K, T], error) {
+	c := &BimodalTTLCache[K, T]{
+		hotTTL:       hotTTL,
+		coldTTL:      coldTTL,
+		hotThreshold: int64(hotThreshold),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.ttlOf = func(e *entry[K, T]) time.Duration {
+			return c.ttlFor(c.count(e.key).Load())
+		}
+		o.onEvicted = func(e *entry[K, T]) {
+			c.counts.Delete(e.key)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *BimodalTTLCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *BimodalTTLCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if e, ok := c.get(key); ok {
+		if value, ok := c.hit(e, read); ok {
+			renewed := *e
+			renewed.ttl = c.ttlFor(c.count(key).Add(1))
+			renewed.expiresAt = time.Now().Add(renewed.ttl)
+			c.store(&renewed)
+			return value, nil
+		}
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *BimodalTTLCache[K, T]) Delete(key K) {
+	c.ristrettoCache.Delete(key)
+	c.counts.Delete(key)
+}
+
+func (c *BimodalTTLCache[K, T]) count(key K) *atomic.Int64 {
+	count, _ := c.counts.LoadOrStore(key, &atomic.Int64{})
+	return count.(*atomic.Int64)
+}
+
+func (c *BimodalTTLCache[K, T]) ttlFor(hits int64) time.Duration {
+	if hits >= c.hotThreshold {
+		return c.hotTTL
+	}
+	return c.coldTTL
+}
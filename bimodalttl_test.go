@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func BenchmarkBimodalTTLHitRatio(b *testing.B) {
+	const hotTTL, coldTTL = 100 * time.Millisecond, time.Millisecond
+	keys := benchmarkKeys(10000, true)
+
+	b.Run("fixed", func(b *testing.B) {
+		c, err := NewCache[int, int]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		c.defaultTTL = coldTTL
+		benchmarkLoads(b, c, keys)
+	})
+	b.Run("bimodal", func(b *testing.B) {
+		c, err := NewCacheWithBimodalTTL[int, int](hotTTL, coldTTL, 3)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkLoads(b, c, keys)
+	})
+}
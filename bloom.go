@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// WithBloomFilter checks keys against a bloom filter before looking them up
+// in ristretto, keys which have never been stored skip ristretto entirely.
+// The filter is sized for capacity keys with the false positive rate fpRate,
+// NewCache fails unless capacity is positive and fpRate between 0 and 1.
+func WithBloomFilter[K comparable, T any](capacity int, fpRate float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.bloomFilter = true
+		o.bloomCapacity = capacity
+		o.bloomFPRate = fpRate
+	}
+}
+
+// ResetBloomFilter clears the bloom filter, all keys have to be read again
+// before they are found in the cache.
+func (c *ristrettoCache[K, T]) ResetBloomFilter() {
+	if c.bloom != nil {
+		c.bloom.reset()
+	}
+}
+
+type bloomFilter struct {
+	bits   []atomic.Uint64
+	size   uint64
+	hashes uint64
+}
+
+func newBloomFilter(capacity int, fpRate float64) *bloomFilter {
+	n := math.Max(float64(capacity), 1)
+	size := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(math.Round(size/n*math.Ln2), 1)
+	return &bloomFilter{
+		bits:   make([]atomic.Uint64, (uint64(size)+63)/64),
+		size:   uint64(size),
+		hashes: uint64(hashes),
+	}
+}
+
+func (f *bloomFilter) add(key interface{}) {
+	h1, h2 := doubleHash(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		word := &f.bits[bit/64]
+		for {
+			old := word.Load()
+			if old&(1<<(bit%64)) != 0 || word.CompareAndSwap(old, old|1<<(bit%64)) {
+				break
+			}
+		}
+	}
+}
+
+func (f *bloomFilter) test(key interface{}) bool {
+	h1, h2 := doubleHash(key)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) reset() {
+	for i := range f.bits {
+		f.bits[i].Store(0)
+	}
+}
+
+// doubleHash derives the two hashes for double hashing from the ristretto
+// key hashes, which are just the key itself for integers.
+func doubleHash(key interface{}) (uint64, uint64) {
+	h1, _ := keyToHash(key)
+	h1 = mix64(h1)
+	return h1, mix64(h1) | 1
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestBloomFilterRejectsInvalidParameters(t *testing.T) {
+	for _, tt := range []struct {
+		capacity int
+		fpRate   float64
+	}{
+		{0, 0.01},
+		{-1, 0.01},
+		{1000, 0},
+		{1000, -0.5},
+		{1000, 1},
+		{1000, 2},
+	} {
+		c, err := NewCache(WithBloomFilter[string, int](tt.capacity, tt.fpRate))
+		if err == nil {
+			c.Close()
+			t.Errorf("capacity %d, false positive rate %g accepted", tt.capacity, tt.fpRate)
+		}
+	}
+}
+
+func TestBloomFilterSkipsKeysNeverStored(t *testing.T) {
+	c, err := NewCache(WithBloomFilter[string, int](1000, 0.01))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("stored", 1)
+	if value, ok := c.Peek("stored"); !ok || value != 1 {
+		t.Errorf("Peek(stored) = %d, %v", value, ok)
+	}
+	if c.bloom.test("never stored") {
+		t.Error("key never stored passes the bloom filter")
+	}
+}
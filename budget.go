@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrBudgetExceeded = errors.New("cost budget exceeded")
+
+type tenantKey[K comparable] struct {
+	tenant string
+	key    K
+}
+
+type budgetOptions struct {
+	budgets map[string]int64
+}
+
+type BudgetOption func(*budgetOptions)
+
+// WithTenantBudget sets the maximum cost of the entries of each tenant,
+// tenants without a budget are not limited.
+func WithTenantBudget(budgets map[string]int64) BudgetOption {
+	return func(o *budgetOptions) {
+		o.budgets = budgets
+	}
+}
+
+// BudgetedCache limits the total cost of the entries stored by each tenant,
+// so a single tenant cannot fill the whole cache.
+type BudgetedCache[K comparable, T any] struct {
+	cache *ristrettoCache[tenantKey[K], T]
+	usage sync.Map
+	budgetOptions
+}
+
+func NewCacheWithBudgetTracking[K comparable, T any](opts ...BudgetOption) (*BudgetedCache[K, T], error) {
+	c := &BudgetedCache[K, T]{}
+	for _, opt := range opts {
+		opt(&c.budgetOptions)
+	}
+	cache, err := NewCache[tenantKey[K], T](func(o *options[tenantKey[K], T]) {
+		o.onSet = func(e *entry[tenantKey[K], T]) {
+			c.tenantUsage(e.key.tenant).Add(e.cost)
+		}
+		o.onExit = func(e *entry[tenantKey[K], T]) {
+			c.tenantUsage(e.key.tenant).Add(-e.cost)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+// LoadOrStoreForTenant returns ErrBudgetExceeded without invoking the reader
+// on a miss when the tenant has used up its budget.
+func (c *BudgetedCache[K, T]) LoadOrStoreForTenant(tenantID string, key K, read reader[T]) (T, error) {
+	return c.cache.LoadOrStoreE(tenantKey[K]{tenant: tenantID, key: key}, func() (T, error) {
+		if budget, ok := c.budgets[tenantID]; ok && c.tenantUsage(tenantID).Load() >= budget {
+			var zero T
+			return zero, ErrBudgetExceeded
+		}
+		return read(), nil
+	})
+}
+
+func (c *BudgetedCache[K, T]) DeleteForTenant(tenantID string, key K) {
+	c.cache.Delete(tenantKey[K]{tenant: tenantID, key: key})
+}
+
+func (c *BudgetedCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+func (c *BudgetedCache[K, T]) tenantUsage(tenantID string) *atomic.Int64 {
+	usage, _ := c.usage.LoadOrStore(tenantID, &atomic.Int64{})
+	return usage.(*atomic.Int64)
+}
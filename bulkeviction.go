@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithBulkEvictionFunc sets the function BulkEvictionCache passes the keys
+// of evicted entries to, in batches of up to maxBatchSize keys at most
+// maxDelay after the first key of the batch was evicted.
+func WithBulkEvictionFunc[K comparable, T any](fn func(keys []K) error, maxBatchSize int, maxDelay time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.bulkEvict = fn
+		o.bulkBatchSize = maxBatchSize
+		o.bulkDelay = maxDelay
+	}
+}
+
+// BulkEvictionCache batches the keys of entries evicted because of their
+// cost or TTL, e.g. to clean up downstream state with one call per batch
+// instead of one per key. Full batches are passed on from ristretto's
+// goroutine, others once their delay passes. Failed calls are counted in
+// Stats.RemoteErrors and their keys are not retried.
+type BulkEvictionCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	errors atomic.Uint64
+
+	mu      sync.Mutex
+	pending []K
+	timer   *time.Timer
+}
+
+func NewCacheWithBulkEviction[K comparable, T any](evictFunc func(keys []K) error, opts ...Option[K, T]) (*BulkEvictionCache[K, T], error) {
+	c := &BulkEvictionCache[K, T]{}
+	cache, err := NewCache(append(append([]Option[K, T]{
+		WithBulkEvictionFunc[K, T](evictFunc, 100, time.Second),
+	}, opts...), func(o *options[K, T]) {
+		o.onEvicted = func(e *entry[K, T]) {
+			c.add(e.key)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *BulkEvictionCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.RemoteErrors = c.errors.Load()
+	return stats
+}
+
+// Close closes the cache and passes on the pending keys.
+func (c *BulkEvictionCache[K, T]) Close() {
+	c.ristrettoCache.Close()
+	c.flush()
+}
+
+func (c *BulkEvictionCache[K, T]) add(key K) {
+	c.mu.Lock()
+	c.pending = append(c.pending, key)
+	if len(c.pending) < c.bulkBatchSize {
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.bulkDelay, c.flush)
+		}
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	c.flush()
+}
+
+func (c *BulkEvictionCache[K, T]) flush() {
+	c.mu.Lock()
+	keys := c.pending
+	c.pending = nil
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mu.Unlock()
+
+	if len(keys) > 0 && c.bulkEvict(keys) != nil {
+		c.errors.Add(1)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkEvictionBatchesKeys(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	flushed := make(chan struct{}, 10)
+	evict := func(keys []int) error {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+		flushed <- struct{}{}
+		return nil
+	}
+	c, err := NewCacheWithBulkEviction(evict, WithBulkEvictionFunc[int, int](evict, 3, 50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for key := 0; key < 7; key++ {
+		c.add(key)
+	}
+	// full batches are passed on right away
+	mu.Lock()
+	got := append([][]int(nil), batches...)
+	mu.Unlock()
+	if want := [][]int{{0, 1, 2}, {3, 4, 5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got batches %v, want %v", got, want)
+	}
+
+	// the remaining key once the delay passes
+	<-flushed
+	<-flushed
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("partial batch not passed on")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []int{6}; !reflect.DeepEqual(batches[2], want) {
+		t.Errorf("got batch %v, want %v", batches[2], want)
+	}
+}
+
+func TestBulkEvictionCloseFlushesPendingKeys(t *testing.T) {
+	var batches [][]string
+	evict := func(keys []string) error {
+		batches = append(batches, keys)
+		return nil
+	}
+	c, err := NewCacheWithBulkEviction(evict, WithBulkEvictionFunc[string, int](evict, 100, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.add("a")
+	c.add("b")
+	c.Close()
+	if want := [][]string{{"a", "b"}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("got batches %v, want %v", batches, want)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync/atomic"
+)
+
+// ByteBudgetCache uses the size of the key plus the size of the gob encoded
+// value as the cost of entries. New entries not fitting into the remaining
+// budget are rejected instead of evicting other entries, misses return the
+// read value along with ErrBudgetExceeded then. Values gob cannot encode are
+// returned with the encoding error and not cached either. Concurrent inserts
+// of different keys can overshoot the budget, ristretto evicts entries then.
+type ByteBudgetCache[K ~string, T any] struct {
+	*ristrettoCache[K, T]
+	budget int64
+	usage  atomic.Int64
+}
+
+func NewCacheWithByteBudget[K ~string, T any](budgetBytes int64, opts ...Option[K, T]) (*ByteBudgetCache[K, T], error) {
+	c := &ByteBudgetCache[K, T]{budget: budgetBytes}
+	cache, err := NewCache(append(opts, WithMaxCost[K, T](budgetBytes), func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.usage.Add(e.cost)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			c.usage.Add(-e.cost)
+		}
+		o.storeMiss = c.storeWithinBudget
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// SetDefault reports false if the value does not fit into the remaining
+// budget or cannot be encoded.
+func (c *ByteBudgetCache[K, T]) SetDefault(key K, value T) bool {
+	if c.keySchema != nil && !c.keySchema(key) {
+		return false
+	}
+	return c.storeWithinBudget(c.newEntry(key, value)) == nil
+}
+
+// RemainingBudget returns the bytes left in the budget.
+func (c *ByteBudgetCache[K, T]) RemainingBudget() int64 {
+	return c.budget - c.usage.Load()
+}
+
+// storeWithinBudget stores the new entry with its size as cost.
+func (c *ByteBudgetCache[K, T]) storeWithinBudget(e *entry[K, T]) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&e.value); err != nil {
+		return err
+	}
+	size := int64(len(e.key) + buf.Len())
+
+	// the replaced entry frees its cost once the new one is stored
+	needed := size
+	if old, ok := c.getStale(e.key); ok {
+		needed -= old.cost
+	}
+	if needed > c.RemainingBudget() {
+		return ErrBudgetExceeded
+	}
+
+	e.cost = size
+	c.setEntry(e)
+	c.cache.Wait()
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestByteBudgetRejectsEntriesAboveTheBudget(t *testing.T) {
+	c, err := NewCacheWithByteBudget[string, string](100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.LoadOrStoreE("small", func() (string, error) { return "v", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Peek("small"); !ok {
+		t.Error("entry within the budget not cached")
+	}
+	remaining := c.RemainingBudget()
+	if remaining >= 100 {
+		t.Errorf("remaining budget %d, want the cost of the cached entry deducted", remaining)
+	}
+
+	value, err := c.LoadOrStoreE("large", func() (string, error) { return strings.Repeat("x", 100), nil })
+	if !errors.Is(err, ErrBudgetExceeded) || len(value) != 100 {
+		t.Errorf("got %d bytes, %v, want the read value and %v", len(value), err, ErrBudgetExceeded)
+	}
+	if _, ok := c.Peek("large"); ok {
+		t.Error("entry above the budget cached")
+	}
+	if c.RemainingBudget() != remaining {
+		t.Error("rejected entry counted in the budget")
+	}
+}
+
+func TestByteBudgetAppliesAllowIf(t *testing.T) {
+	c, err := NewCacheWithByteBudget(100, WithAllowIf(func(string, string) bool { return false }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if value := c.LoadOrStore("k", func() string { return "v" }); value != "v" {
+		t.Errorf("got %q, want %q", value, "v")
+	}
+	if _, ok := c.Peek("k"); ok {
+		t.Error("value rejected by WithAllowIf cached")
+	}
+}
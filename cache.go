@@ -0,0 +1,907 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
+	"golang.org/x/sync/semaphore"
+)
+
+// Cache is a read-through cache, values missing in the cache are read with
+// the reader passed to LoadOrStore.
+type Cache[K comparable, T any] interface {
+	LoadOrStore(key K, read reader[T]) T
+	Peek(key K) (T, bool)
+	SetDefault(key K, value T) bool
+	Delete(key K)
+}
+
+var ErrDraining = errors.New("cache is draining")
+
+type options[K comparable, T any] struct {
+	maxCost         int64
+	preloader       func() map[K]T
+	onEvict         func(key K, value T)
+	evictionWorkers int
+	evictionRate    float64
+	evictionSample  float64
+	sizer           Sizer[T]
+	costEstimator   func(key K, value T) int64
+	mutationLog     MutationLog[K, T]
+	copier          Copier[T]
+	fanout          []Cache[K, T]
+	staleTTL        time.Duration
+	healthProbe     func() error
+	healthInterval  time.Duration
+	standby         Cache[K, T]
+	onChange        func(key K, oldValue, newValue T)
+	eq              func(a, b T) bool
+	sliding         bool
+	allowIf         []func(key K, value T) bool
+	validators      []func(value T) error
+	minRetention    int
+	maxItems        int
+	auditFraction   float64
+	auditMismatch   func(key K, cached, fresh T)
+	bloomFilter     bool
+	bloomCapacity   int
+	bloomFPRate     float64
+	repair          func(key K, value T) (T, bool)
+	repairFraction  float64
+	ttlBySize       func(size int64) time.Duration
+	fallbackTTL     time.Duration
+	remoteRegions   []RemoteCache[K, T]
+	remoteParallel  int
+	generation      int
+	onHit           func(key K, value T)
+	asyncOnHit      bool
+	deadLetters     chan<- FailedEntry[K, T]
+	preEviction     func(key K, value T) (newCost int64, keepEntry bool)
+	retention       func(key K, value T, stats EntryStats) bool
+	asyncWriter     AsyncWriter[K, T]
+	writeBatchSize  int
+	writeInterval   time.Duration
+	costDecay       float64
+	canonicalize    func(key K) K
+	dedupBudget     int
+	walMaxSize      int64
+	targetHitRate   float64
+	minTTL          time.Duration
+	maxTTL          time.Duration
+	hitRateWindow   int
+	hitRateEvery    int
+	hitRateObserver func(rate float64)
+	keySchema       func(key K) bool
+	counterInterval time.Duration
+	schemaVersion   uint8
+	maxEntryCost    int64
+	softMaxCost     int64
+	readers         *semaphore.Weighted
+	onExpired       func(key K, value T)
+	hotKeyReset     time.Duration
+	maxDeltas       int
+	gcLeakDetection bool
+	cost0Bypass     bool
+	capacityWarning float64
+	onCapacity      func()
+	retryInterval   time.Duration
+	maxRetry        time.Duration
+	replicas        int
+	diskTTL         time.Duration
+	bearerToken     string
+	bulkEvict       func(keys []K) error
+	bulkBatchSize   int
+	bulkDelay       time.Duration
+	hotDuration     time.Duration
+	expireIf        func(key K, value T) bool
+	expiryScan      time.Duration
+	expiryScanRate  float64
+
+	// hooks for caches built on top of ristrettoCache
+	onSet   func(e *entry[K, T])
+	onExit  func(e *entry[K, T])
+	isFresh func(e *entry[K, T]) bool
+	ttlOf   func(e *entry[K, T]) time.Duration
+	// onCollision is called when an entry stored under another key is found,
+	// the entry is treated as a miss
+	onCollision func(key K, e *entry[K, T])
+	// onEvicted is called for entries evicted because of their cost or TTL
+	onEvicted func(e *entry[K, T])
+	// onReject is called for entries rejected by the admission policy
+	onReject func(e *entry[K, T])
+	// invokeMiss replaces invoking the reader of a miss, e.g. to give up on
+	// slow readers
+	invokeMiss func(key K, read readerE[T]) (T, error)
+	// storeMiss replaces storing the entries read on misses and waiting for
+	// ristretto to apply them, its error is returned to the caller
+	storeMiss func(e *entry[K, T]) error
+}
+
+// Option configures a cache created by NewCache.
+type Option[K comparable, T any] func(*options[K, T])
+
+// WithMaxCost sets the maximum total cost of the entries in the cache.
+func WithMaxCost[K comparable, T any](maxCost int64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.maxCost = maxCost
+	}
+}
+
+// WithStaleTTL keeps entries in the cache for staleTTL after they expire, so
+// they can still be served as stale values.
+func WithStaleTTL[K comparable, T any](staleTTL time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.staleTTL = staleTTL
+	}
+}
+
+// WithEq sets the function used to compare values, reflect.DeepEqual is used
+// by default.
+func WithEq[K comparable, T any](eq func(a, b T) bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.eq = eq
+	}
+}
+
+// WithChangeNotification calls fn in a new goroutine when a newly read value
+// differs from the value cached before. Values are only compared while the
+// old value is still cached, see WithStaleTTL.
+func WithChangeNotification[K comparable, T any](fn func(key K, oldValue, newValue T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.onChange = fn
+	}
+}
+
+// WithSlidingExpiration resets the TTL of entries on every hit, so entries
+// which are accessed often never expire. Note that every hit becomes a write
+// to ristretto.
+func WithSlidingExpiration[K comparable, T any](sliding bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.sliding = sliding
+	}
+}
+
+// WithAllowIf caches only values for which fn returns true, other values are
+// returned to the caller without being stored. Predicates of multiple
+// WithAllowIf options must all return true.
+func WithAllowIf[K comparable, T any](fn func(key K, value T) bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.allowIf = append(o.allowIf, fn)
+	}
+}
+
+// AllowIfNonNil is a WithAllowIf predicate which does not cache nil pointers.
+func AllowIfNonNil[K comparable, E any](_ K, value *E) bool {
+	return value != nil
+}
+
+// AllowIfNonEmpty is a WithAllowIf predicate which does not cache empty
+// slices.
+func AllowIfNonEmpty[K comparable, E any](_ K, value []E) bool {
+	return len(value) > 0
+}
+
+// WithEvictionSampling calls the callback set by WithOnEvict only for the
+// fraction sampleRate of evictions, picked at random. Stats still count all
+// evictions.
+func WithEvictionSampling[K comparable, T any](sampleRate float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.evictionSample = sampleRate
+	}
+}
+
+// WithAuditFraction reads the value again on the fraction f of hits and
+// reports values which differ from the cached one to the callback set by
+// WithAuditMismatch. Audits run in their own goroutine and never change the
+// cached or returned value. They call the reader passed by the caller
+// without the side effects caches add to misses, caches storing values of
+// another type than their readers return do not audit.
+func WithAuditFraction[K comparable, T any](f float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.auditFraction = f
+	}
+}
+
+func WithAuditMismatch[K comparable, T any](fn func(key K, cached, fresh T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.auditMismatch = fn
+	}
+}
+
+// WithReadRepair verifies cached values on hits, see WithRepairFraction. If
+// the verifier returns false, the entry is deleted and read again, otherwise
+// the value returned by the verifier is returned to the caller.
+func WithReadRepair[K comparable, T any](verifier func(key K, value T) (T, bool)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.repair = verifier
+	}
+}
+
+// WithRepairFraction sets the fraction of hits verified by the WithReadRepair
+// verifier, all hits are verified by default.
+func WithRepairFraction[K comparable, T any](f float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.repairFraction = f
+	}
+}
+
+// WithPreloader populates the cache with the returned entries before NewCache
+// returns.
+func WithPreloader[K comparable, T any](fn func() map[K]T) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.preloader = fn
+	}
+}
+
+// WithOnEvict registers a callback for entries evicted by ristretto because
+// of their TTL or cost.
+func WithOnEvict[K comparable, T any](fn func(key K, value T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.onEvict = fn
+	}
+}
+
+// WithSizer uses the size of values as their cost instead of 1.
+func WithSizer[K comparable, T any](sizer Sizer[T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.sizer = sizer
+	}
+}
+
+// WithMutationLog logs every value stored in or deleted from the cache.
+func WithMutationLog[K comparable, T any](log MutationLog[K, T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.mutationLog = log
+	}
+}
+
+// NewCache returns a cache keyed by any comparable type. It uses the same
+// per-key locking as syncMapCache.
+func NewCache[K comparable, T any](opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	o := options[K, T]{
+		maxCost:        1 << 30,
+		repairFraction: 1,
+		evictionSample: 1,
+		eq: func(a, b T) bool {
+			return reflect.DeepEqual(a, b)
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.bloomFilter && o.bloomCapacity <= 0 {
+		return nil, fmt.Errorf("bloom filter capacity must be positive, got %d", o.bloomCapacity)
+	}
+	if o.bloomFilter && !(o.bloomFPRate > 0 && o.bloomFPRate < 1) {
+		return nil, fmt.Errorf("bloom filter false positive rate must be between 0 and 1, got %g", o.bloomFPRate)
+	}
+
+	c := &ristrettoCache[K, T]{
+		defaultTTL: ttl,
+		options:    o,
+	}
+	config := &ristretto.Config{
+		NumCounters: 1e7, // number of keys to track frequency of (10M).
+		MaxCost:     o.maxCost,
+		BufferItems: 64, // number of keys per Get buffer.
+		KeyToHash:   keyToHash,
+		// MaxCost is a budget for the costs of the entries only
+		IgnoreInternalCost: true,
+		// metrics track the cost of the cache for WithCapacityWarning
+		Metrics: o.onCapacity != nil,
+	}
+	config.OnEvict = func(item *ristretto.Item) {
+		// Close evicts the buffered deletes too, which have no value
+		if e, ok := item.Value.(*entry[K, T]); ok {
+			c.evict(e)
+		}
+	}
+	if o.onExit != nil {
+		config.OnExit = func(val interface{}) {
+			o.onExit(val.(*entry[K, T]))
+		}
+	}
+	if o.deadLetters != nil || o.onReject != nil {
+		config.OnReject = func(item *ristretto.Item) {
+			e := item.Value.(*entry[K, T])
+			if o.deadLetters != nil {
+				c.deadLetter(e, ErrRejected)
+			}
+			if o.onReject != nil {
+				o.onReject(e)
+			}
+		}
+	}
+
+	cache, err := ristretto.NewCache(config)
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	if o.hitRateObserver != nil {
+		c.hitRate = newRollingHitRate(o.hitRateWindow, o.hitRateEvery)
+	}
+	if o.bloomFilter {
+		c.bloom = newBloomFilter(o.bloomCapacity, o.bloomFPRate)
+	}
+
+	if o.preloader != nil {
+		if err := c.preload(o.preloader); err != nil {
+			cache.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// entry is the value stored in ristretto, it keeps the key for callbacks.
+type entry[K comparable, T any] struct {
+	key  K
+	cost int64
+	ttl  time.Duration
+	// access is only tracked with WithRetentionHeuristic and
+	// WithDynamicCostDecay, it is shared by the copies of the entry
+	access *entryAccess
+	itemValue[T]
+}
+
+type ristrettoCache[K comparable, T any] struct {
+	cache      *ristretto.Cache
+	locks      sync.Map
+	defaultTTL time.Duration
+	bloom      *bloomFilter
+	versions   atomic.Uint64
+	dryRuns    atomic.Uint64
+	dlqDropped atomic.Uint64
+	evictions  atomic.Uint64
+	hitRate    *rollingHitRate
+	// bypass holds the entries of cost 0 with WithCost0Bypass
+	bypass     sync.Map
+	middleware []Middleware[K, T]
+	chain      LoadOrStoreFunc[K, T]
+
+	// inFlight counts the readers in progress, drainMu orders adding to it
+	// with DrainAndClose waiting for it
+	drainMu  sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	// closing is set while Close evicts the entries, ristretto holds the
+	// locks of its store meanwhile
+	closing atomic.Bool
+
+	options[K, T]
+}
+
+func (c *ristrettoCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.loadOrStoreHit(key, read)
+	return value
+}
+
+// loadOrStoreHit works like LoadOrStore and reports whether the value was
+// cached.
+func (c *ristrettoCache[K, T]) loadOrStoreHit(key K, read reader[T]) (T, bool) {
+	if c.chain != nil {
+		return c.chain(key, read)
+	}
+	return c.loadOrStore(key, read)
+}
+
+func (c *ristrettoCache[K, T]) loadOrStore(key K, read reader[T]) (T, bool) {
+	value, hit, _ := c.loadOrStoreE(key, read.withError())
+	return value, hit
+}
+
+// LoadOrStoreE works like LoadOrStore, but values whose reader fails are not
+// stored and the error is returned to the caller.
+func (c *ristrettoCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreE(key, read)
+	return value, err
+}
+
+// loadOrStoreE works like LoadOrStoreE and reports whether the value was
+// cached.
+func (c *ristrettoCache[K, T]) loadOrStoreE(key K, read readerE[T]) (T, bool, error) {
+	return c.loadOrStoreSource(key, read, read)
+}
+
+// loadOrStoreSource works like loadOrStoreE for caches wrapping the reader
+// of the caller in side effects which must only run on misses. Audits call
+// source, the reader of the caller, instead of read. Caches storing values
+// of another type than source reads pass nil, their hits are not audited.
+func (c *ristrettoCache[K, T]) loadOrStoreSource(key K, read, source readerE[T]) (T, bool, error) {
+	if c.keySchema != nil && !c.keySchema(key) {
+		var zero T
+		return zero, false, ErrInvalidKey
+	}
+	if e, ok := c.get(key); ok {
+		if value, ok := c.hit(e, source); ok {
+			c.observeHit(true)
+			return value, true, nil
+		}
+	}
+
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.get(key); ok {
+		c.observeHit(true)
+		return value.value, true, nil
+	}
+
+	c.observeHit(false)
+	var value T
+	var err error
+	if c.invokeMiss != nil {
+		value, err = c.invokeMiss(key, read)
+	} else {
+		value, err = c.invoke(read)
+	}
+	if err != nil {
+		return value, false, err
+	}
+	return value, false, c.storeRead(key, value)
+}
+
+// storeRead applies the options acting on values read on misses and stores
+// the value unless one of them rejects it. The caller holds the lock of the
+// key.
+func (c *ristrettoCache[K, T]) storeRead(key K, value T) error {
+	for _, validate := range c.validators {
+		if err := validate(value); err != nil {
+			return err
+		}
+	}
+	for _, allow := range c.allowIf {
+		if !allow(key, value) {
+			return nil
+		}
+	}
+	e := c.newEntry(key, value)
+	if c.tooLarge(e) {
+		return ErrEntryTooLarge
+	}
+	if c.onChange != nil {
+		if old, ok := c.getStale(key); ok && !c.eq(old.value, value) {
+			go c.onChange(key, old.value, value)
+		}
+	}
+	if c.storeMiss != nil {
+		return c.storeMiss(e)
+	}
+	c.setEntry(e)
+	c.cache.Wait()
+	return nil
+}
+
+// LoadOrStoreBatch loads all keys concurrently and returns their values in
+// the order of keys.
+func (c *ristrettoCache[K, T]) LoadOrStoreBatch(keys []K, read reader[T]) []T {
+	var wg sync.WaitGroup
+	values := make([]T, len(keys))
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key K) {
+			defer wg.Done()
+			values[i] = c.LoadOrStore(key, read)
+		}(i, key)
+	}
+	wg.Wait()
+	return values
+}
+
+// DryRun calls read and returns its value without looking it up in the cache
+// or storing it, e.g. for canary testing a new reader. Dry runs are counted
+// separately from hits and misses.
+func (c *ristrettoCache[K, T]) DryRun(key K, read reader[T]) T {
+	c.dryRuns.Add(1)
+	return read()
+}
+
+func (c *ristrettoCache[K, T]) Stats() Stats {
+	return Stats{
+		DryRunCalls: c.dryRuns.Load(),
+		DLQDropped:  c.dlqDropped.Load(),
+		Evictions:   c.evictions.Load(),
+	}
+}
+
+// Peek returns the cached value without reading it on a miss.
+func (c *ristrettoCache[K, T]) Peek(key K) (T, bool) {
+	value, ok := c.get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return value.value, true
+}
+
+// SetDefault stores the value with the default TTL and reports whether it
+// was accepted by ristretto.
+func (c *ristrettoCache[K, T]) SetDefault(key K, value T) bool {
+	if c.keySchema != nil && !c.keySchema(key) {
+		return false
+	}
+	ok := c.set(key, value)
+	c.cache.Wait()
+	return ok
+}
+
+// SetCost updates the cost of a cached entry keeping its value and
+// expiration, it returns false if the key is not cached.
+func (c *ristrettoCache[K, T]) SetCost(key K, cost int64) bool {
+	e, ok := c.getStale(key)
+	if !ok || !c.storeWithCost(e, cost) {
+		return false
+	}
+	c.cache.Wait()
+	return true
+}
+
+func (c *ristrettoCache[K, T]) Delete(key K) {
+	c.cache.Del(key)
+	if c.cost0Bypass {
+		c.deleteBypass(key)
+	}
+	if c.mutationLog != nil {
+		c.mutationLog.LogDelete(key)
+	}
+}
+
+// Close stops ristretto's goroutines, the cache must not be used afterwards.
+func (c *ristrettoCache[K, T]) Close() {
+	c.closing.Store(true)
+	c.cache.Close()
+}
+
+// DrainAndClose stops invoking readers, LoadOrStoreE returns ErrDraining on
+// misses from now on, waits for the readers in progress and closes the cache.
+// If ctx is done first, the cache is closed right away and ctx.Err() is
+// returned.
+func (c *ristrettoCache[K, T]) DrainAndClose(ctx context.Context) error {
+	c.drainMu.Lock()
+	c.draining = true
+	c.drainMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		c.Close()
+		return nil
+	case <-ctx.Done():
+		c.Close()
+		return ctx.Err()
+	}
+}
+
+func (c *ristrettoCache[K, T]) invoke(read readerE[T]) (T, error) {
+	c.drainMu.Lock()
+	if c.draining {
+		c.drainMu.Unlock()
+		var zero T
+		return zero, ErrDraining
+	}
+	c.inFlight.Add(1)
+	c.drainMu.Unlock()
+	defer c.inFlight.Done()
+
+	if c.readers != nil {
+		if err := c.readers.Acquire(context.Background(), 1); err != nil {
+			var zero T
+			return zero, err
+		}
+		defer c.readers.Release(1)
+	}
+	return read()
+}
+
+// hit applies the options acting on cache hits, it returns false if the
+// entry has to be read again. Audits call read unless it is nil.
+func (c *ristrettoCache[K, T]) hit(e *entry[K, T], read readerE[T]) (T, bool) {
+	if c.repair != nil && rand.Float64() < c.repairFraction {
+		value, ok := c.repair(e.key, e.value)
+		if !ok {
+			c.Delete(e.key)
+			return value, false
+		}
+		e = &entry[K, T]{key: e.key, cost: e.cost, ttl: e.ttl, access: e.access, itemValue: e.itemValue}
+		e.value = value
+	}
+	if e.access != nil {
+		if hits := e.access.hit(); c.costDecay > 0 && hits%costDecayHits == 0 {
+			e = c.decayCost(e)
+		}
+	}
+	if c.sliding {
+		c.renew(e)
+	}
+	if c.auditMismatch != nil && read != nil && rand.Float64() < c.auditFraction {
+		go c.audit(e.key, e.value, read)
+	}
+	if c.onHit != nil {
+		if c.asyncOnHit {
+			go c.onHit(e.key, e.value)
+		} else {
+			c.onHit(e.key, e.value)
+		}
+	}
+	return e.value, true
+}
+
+// evict is called for entries ristretto evicted because of their cost or TTL,
+// the hooks may store them again unless the cache closes.
+func (c *ristrettoCache[K, T]) evict(e *entry[K, T]) {
+	// ristretto holds the locks of its store while Close evicts the entries,
+	// storing them again would deadlock
+	closing := c.closing.Load()
+	if c.preEviction != nil && !closing {
+		if cost, keep := c.preEviction(e.key, e.value); keep && c.storeWithCost(e, cost) {
+			return
+		}
+	}
+	if c.retention != nil && e.access != nil && !closing {
+		if c.retention(e.key, e.value, e.access.stats(e.computedAt, e.cost)) && c.storeWithCost(e, 2*e.cost) {
+			return
+		}
+	}
+
+	c.evictions.Add(1)
+	if c.onEvict != nil && (c.evictionSample >= 1 || rand.Float64() < c.evictionSample) {
+		c.onEvict(e.key, e.value)
+	}
+	if c.onExpired != nil && e.expired(c.staleTTL) {
+		c.onExpired(e.key, e.value)
+	}
+	if c.onEvicted != nil {
+		c.onEvicted(e)
+	}
+	if globalEvictionCallbacks.count.Load() > 0 {
+		globalEvictionCallbacks.broadcast(e.key, e.value)
+	}
+}
+
+func (c *ristrettoCache[K, T]) audit(key K, cached T, read readerE[T]) {
+	fresh, err := read()
+	if err == nil && !c.eq(cached, fresh) {
+		c.auditMismatch(key, cached, fresh)
+	}
+}
+
+func (c *ristrettoCache[K, T]) keyLock(key K) *sync.Mutex {
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	return anyLock.(*sync.Mutex)
+}
+
+// lockKeys locks all keys in a stable order, so goroutines locking
+// overlapping sets of keys cannot deadlock, and returns a function unlocking
+// them.
+func (c *ristrettoCache[K, T]) lockKeys(keys []K) func() {
+	sorted := make([]K, 0, len(keys))
+	seen := map[K]bool{}
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return fmt.Sprintf("%#v", sorted[i]) < fmt.Sprintf("%#v", sorted[j])
+	})
+
+	locks := make([]*sync.Mutex, len(sorted))
+	for i, key := range sorted {
+		locks[i] = c.keyLock(key)
+		locks[i].Lock()
+	}
+	return func() {
+		for _, lock := range locks {
+			lock.Unlock()
+		}
+	}
+}
+
+func (c *ristrettoCache[K, T]) get(key K) (*entry[K, T], bool) {
+	e, ok := c.getStale(key)
+	if !ok || c.staleTTL > 0 && time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	if c.isFresh != nil && !c.isFresh(e) {
+		return nil, false
+	}
+	return e, true
+}
+
+// getStale returns the entry even if it has expired but is kept because of
+// the stale TTL.
+func (c *ristrettoCache[K, T]) getStale(key K) (*entry[K, T], bool) {
+	if c.cost0Bypass {
+		if e, ok := c.loadBypass(key); ok {
+			return e, true
+		}
+	}
+	if c.bloom != nil && !c.bloom.test(key) {
+		return nil, false
+	}
+	val, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := val.(*entry[K, T])
+	if c.onCollision != nil && e.key != key {
+		c.onCollision(key, e)
+		return nil, false
+	}
+	return e, true
+}
+
+// stored returns the entry ristretto stores for the key, it does not count as
+// a hit or miss. Hooks use it to tell updates, which call onExit with the
+// replaced entry, from entries leaving the cache. Nothing is stored while the
+// cache closes.
+func (c *ristrettoCache[K, T]) stored(key K) (*entry[K, T], bool) {
+	if c.closing.Load() {
+		return nil, false
+	}
+	val, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return val.(*entry[K, T]), true
+}
+
+// renew stores a copy of the entry with a new TTL, entries are shared with
+// other goroutines and must not be modified.
+func (c *ristrettoCache[K, T]) renew(e *entry[K, T]) {
+	renewed := *e
+	renewed.expiresAt = time.Now().Add(e.ttl)
+	c.store(&renewed)
+}
+
+func (c *ristrettoCache[K, T]) set(key K, value T) bool {
+	return c.setEntry(c.newEntry(key, value))
+}
+
+func (c *ristrettoCache[K, T]) setWithTTL(key K, value T, ttl time.Duration) bool {
+	e := c.newEntry(key, value)
+	e.ttl = ttl
+	e.expiresAt = e.computedAt.Add(ttl)
+	return c.setEntry(e)
+}
+
+func (c *ristrettoCache[K, T]) newEntry(key K, value T) *entry[K, T] {
+	e := &entry[K, T]{key: key, cost: 1, ttl: c.defaultTTL, itemValue: itemValue[T]{value: value}}
+	if c.sizer != nil {
+		e.cost = c.sizer.Size(value)
+		if c.ttlBySize != nil {
+			e.ttl = c.ttlBySize(e.cost)
+		}
+	}
+	if c.costEstimator != nil {
+		e.cost = c.costEstimator(key, value)
+	}
+	if c.ttlOf != nil {
+		e.ttl = c.ttlOf(e)
+	}
+	e.version = c.versions.Add(1)
+	e.generation = c.generation
+	if c.retention != nil || c.costDecay > 0 {
+		e.access = &entryAccess{}
+	}
+	e.computedAt = time.Now()
+	e.expiresAt = e.computedAt.Add(e.ttl)
+	return e
+}
+
+func (c *ristrettoCache[K, T]) setEntry(e *entry[K, T]) bool {
+	if c.tooLarge(e) {
+		return false
+	}
+	if !c.store(e) {
+		if c.deadLetters != nil {
+			c.deadLetter(e, ErrSetDropped)
+		}
+		return false
+	}
+	if c.mutationLog != nil {
+		c.mutationLog.LogSet(e.key, e.value, e.ttl)
+	}
+	return true
+}
+
+func (c *ristrettoCache[K, T]) store(e *entry[K, T]) bool {
+	if c.cost0Bypass {
+		if e.cost == 0 {
+			return c.storeBypass(e)
+		}
+		c.deleteBypass(e.key)
+	}
+	if !c.cache.SetWithTTL(e.key, e, e.cost, e.ttl+c.staleTTL) {
+		return false
+	}
+	if c.bloom != nil {
+		c.bloom.add(e.key)
+	}
+	if c.onSet != nil {
+		c.onSet(e)
+	}
+	return true
+}
+
+// storeWithCost stores a copy of the entry with a new cost, keeping its
+// remaining TTL.
+func (c *ristrettoCache[K, T]) storeWithCost(e *entry[K, T], cost int64) bool {
+	updated := *e
+	updated.cost = cost
+
+	// zero TTLs never expire
+	var ttl time.Duration
+	if e.ttl > 0 {
+		if ttl = time.Until(e.expiresAt) + c.staleTTL; ttl <= 0 {
+			return false
+		}
+	}
+	if !c.cache.SetWithTTL(e.key, &updated, cost, ttl) {
+		return false
+	}
+	if c.onSet != nil {
+		c.onSet(&updated)
+	}
+	return true
+}
+
+func (c *ristrettoCache[K, T]) preload(preloader func() map[K]T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("preloader panicked: %v", r)
+		}
+	}()
+
+	for key, value := range preloader() {
+		if !c.set(key, value) {
+			// the set buffer is full, let it drain and try again
+			c.cache.Wait()
+			c.set(key, value)
+		}
+	}
+	c.cache.Wait()
+	return nil
+}
+
+func (read reader[T]) withError() readerE[T] {
+	return func() (T, error) {
+		return read(), nil
+	}
+}
+
+// hashedKey is implemented by key types hashing themselves.
+type hashedKey interface {
+	keyToHash() (uint64, uint64)
+}
+
+// keyToHash extends ristretto's key hashing to every comparable key type by
+// hashing the Go-syntax representation of keys ristretto does not support.
+func keyToHash(key interface{}) (uint64, uint64) {
+	switch k := key.(type) {
+	case nil, uint64, string, []byte, byte, int, int32, uint32, int64:
+		return z.KeyToHash(key)
+	case hashedKey:
+		return k.keyToHash()
+	}
+	return z.KeyToHash(fmt.Sprintf("%#v", key))
+}
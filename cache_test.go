@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainAndCloseWaitsForNestedReaders(t *testing.T) {
+	c, err := NewCache[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan string)
+	go func() {
+		done <- c.LoadOrStore("outer", func() string {
+			close(started)
+			<-release
+			// a reader loading another key must not block on the drain
+			return c.LoadOrStore("inner", func() string { return "inner" }) + "-outer"
+		})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	drained := make(chan error)
+	go func() {
+		drained <- c.DrainAndClose(ctx)
+	}()
+
+	// give DrainAndClose time to start waiting before the reader continues
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case value := <-done:
+		if value != "-outer" {
+			t.Errorf("got %q, want the inner reader to be refused while draining", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reader did not return")
+	}
+	if err := <-drained; err != nil {
+		t.Errorf("DrainAndClose: %v", err)
+	}
+}
+
+func TestDrainAndCloseRefusesNewReaders(t *testing.T) {
+	c, err := NewCache[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DrainAndClose(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.invoke(func() (string, error) { return "v", nil }); !errors.Is(err, ErrDraining) {
+		t.Errorf("got %v, want %v", err, ErrDraining)
+	}
+}
+
+func TestMaxCostCountsEntryCostsOnly(t *testing.T) {
+	c, err := NewCache(WithMaxCost[string, int](100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		if !c.SetDefault(strconv.Itoa(i), i) {
+			t.Fatalf("set %d dropped", i)
+		}
+	}
+	cached := 0
+	for i := 0; i < 100; i++ {
+		if _, ok := c.Peek(strconv.Itoa(i)); ok {
+			cached++
+		}
+	}
+	if cached != 100 {
+		t.Errorf("%d of 100 entries of cost 1 cached with a max cost of 100", cached)
+	}
+}
+
+func TestPreloadedKeysArePeeked(t *testing.T) {
+	c, err := NewCache(WithPreloader(func() map[string]int {
+		return map[string]int{"a": 1, "b": 2, "c": 3}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for key, want := range map[string]int{"a": 1, "b": 2, "c": 3} {
+		if value, ok := c.Peek(key); !ok || value != want {
+			t.Errorf("Peek(%q) = %d, %v, want %d", key, value, ok, want)
+		}
+	}
+}
+
+func TestPanickingPreloaderFailsConstruction(t *testing.T) {
+	_, err := NewCache(WithPreloader(func() map[string]int {
+		panic("no values")
+	}))
+	if err == nil {
+		t.Error("constructor succeeded")
+	}
+}
+
+func TestSlidingExpirationKeepsAccessedEntries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("takes 10s")
+	}
+	c, err := NewCache(WithSlidingExpiration[string, int](true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.defaultTTL = 2 * time.Second
+
+	reads := 0
+	read := func() int {
+		reads++
+		return reads
+	}
+	c.LoadOrStore("k", read)
+	for i := 0; i < 10; i++ {
+		time.Sleep(time.Second)
+		if value := c.LoadOrStore("k", read); value != 1 {
+			t.Fatalf("entry expired after %ds", i+1)
+		}
+	}
+}
+
+// hitCounter loads keys through a cache and reports the hits and misses it
+// counted.
+type hitCounter struct {
+	load   func(key string, read reader[int]) int
+	counts func() (hits, misses uint64)
+}
+
+func TestHitsAreCountedWithAudits(t *testing.T) {
+	for name, newCounter := range map[string]func(t *testing.T, opts ...Option[string, int]) hitCounter{
+		"metrics middleware": func(t *testing.T, opts ...Option[string, int]) hitCounter {
+			c := newTestCache(t, opts...)
+			var hits, misses atomic.Uint64
+			c.Use(MetricsMiddleware[string, int](func(_ string, hit bool, _ time.Duration) {
+				if hit {
+					hits.Add(1)
+				} else {
+					misses.Add(1)
+				}
+			}))
+			return hitCounter{c.LoadOrStore, func() (uint64, uint64) { return hits.Load(), misses.Load() }}
+		},
+		"traced": func(t *testing.T, opts ...Option[string, int]) hitCounter {
+			var log bytes.Buffer
+			c := NewTraced[string, int](newTestCache(t, opts...), slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug})))
+			return hitCounter{c.LoadOrStore, func() (uint64, uint64) {
+				return uint64(strings.Count(log.String(), "hit=true")), uint64(strings.Count(log.String(), "hit=false"))
+			}}
+		},
+		"adaptive TTL": func(t *testing.T, opts ...Option[string, int]) hitCounter {
+			c, err := NewCacheWithHitRateAdaptiveTTL(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(c.Close)
+			return hitCounter{c.LoadOrStore, func() (uint64, uint64) { return c.hits.Load(), c.misses.Load() }}
+		},
+		"sampled stats": func(t *testing.T, opts ...Option[string, int]) hitCounter {
+			c, err := NewCacheWithStatisticalSampling(1, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(c.Close)
+			return hitCounter{c.LoadOrStore, func() (uint64, uint64) {
+				stats := c.Stats()
+				return stats.Hits, stats.Misses
+			}}
+		},
+		"A/B testing": func(t *testing.T, opts ...Option[string, int]) hitCounter {
+			c := NewCacheWithABTesting[string, int](0, newTestCache(t, opts...), newTestCache(t, opts...))
+			return hitCounter{c.LoadOrStore, func() (uint64, uint64) {
+				a, _ := c.CompareStats()
+				return a.Hits, a.Misses
+			}}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var audited sync.WaitGroup
+			c := newCounter(t,
+				WithAuditFraction[string, int](1),
+				WithAuditMismatch(func(string, int, int) { audited.Done() }),
+			)
+			var reads atomic.Int64
+			read := func() int {
+				return int(reads.Add(1))
+			}
+			c.load("k", read)
+			audited.Add(2)
+			c.load("k", read)
+			c.load("k", read)
+			// the audits read the key again after the hits were counted
+			audited.Wait()
+
+			if hits, misses := c.counts(); hits != 2 || misses != 1 {
+				t.Errorf("got %d hits and %d misses, want 2 and 1", hits, misses)
+			}
+		})
+	}
+}
+
+func newTestCache(t *testing.T, opts ...Option[string, int]) *ristrettoCache[string, int] {
+	c, err := NewCache(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestAuditsSkipTheSideEffectsOfMisses(t *testing.T) {
+	audited := make(chan struct{}, 1)
+	c, err := NewCacheWithCooldown(time.Hour,
+		WithAuditFraction[string, int](1),
+		WithAuditMismatch(func(string, int, int) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.LoadOrStoreE("k", func() (int, error) { return 1, nil })
+	c.LoadOrStoreE("k", func() (int, error) {
+		defer func() { audited <- struct{}{} }()
+		return 0, errors.New("source unavailable")
+	})
+	<-audited
+
+	// the failed audit must not put the key into its cooldown
+	c.Delete("k")
+	if _, err := c.LoadOrStoreE("k", func() (int, error) { return 2, nil }); err != nil {
+		t.Errorf("miss after a failed audit: %v", err)
+	}
+}
+
+func TestEncryptedHitsAreNotAudited(t *testing.T) {
+	var key [32]byte
+	rand.Read(key[:])
+	mismatches := 0
+	c, err := NewCacheWithEncryption[string, string](key,
+		WithAuditFraction[string, []byte](1),
+		WithAuditMismatch(func(string, []byte, []byte) { mismatches++ }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	reads := 0
+	read := func() (string, error) {
+		reads++
+		return "v", nil
+	}
+	c.LoadOrStoreE("k", read)
+	c.LoadOrStoreE("k", read)
+	// the hit starts no audit, which would compare ciphertexts of different
+	// nonces
+	if reads != 1 || mismatches != 0 {
+		t.Errorf("%d reads and %d mismatches, want 1 and 0", reads, mismatches)
+	}
+}
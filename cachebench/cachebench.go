@@ -0,0 +1,160 @@
+// Package cachebench measures how caches behave when many goroutines load the
+// same keys concurrently with slow readers.
+package cachebench
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoadOrStoreFunc returns the cached value of the key, reading it with read
+// on a miss.
+type LoadOrStoreFunc func(key int, read func() string) string
+
+// Strategy is a cache implementation under benchmark, New is called for
+// every sleep to start with an empty cache.
+type Strategy struct {
+	Name string
+	New  func() LoadOrStoreFunc
+}
+
+type BenchmarkConfig struct {
+	// Rounds is the number of times every strategy is run
+	Rounds int
+	// Routines is the number of goroutines and keys, each goroutine loads
+	// its own key Reps times per round
+	Routines int
+	Reps     int
+	// Sleeps are the reader durations to benchmark
+	Sleeps     []time.Duration
+	Strategies []Strategy
+}
+
+type BenchmarkResult struct {
+	Sleeps []SleepResult
+}
+
+type SleepResult struct {
+	Sleep      time.Duration
+	Duration   time.Duration
+	Strategies []StrategyResult
+}
+
+type StrategyResult struct {
+	Name string
+	// ReadCount is the number of reader calls
+	ReadCount int64
+	// Duration is the total duration of all rounds
+	Duration time.Duration
+	// P99Latency is the upper bound of the 99th percentile of the
+	// LoadOrStore latency, rounded up to a power of two nanoseconds
+	P99Latency time.Duration
+}
+
+// Benchmark runs all strategies with every sleep of the config. Within a
+// round the strategies run one after another.
+func Benchmark(config BenchmarkConfig) BenchmarkResult {
+	var result BenchmarkResult
+	for _, sleep := range config.Sleeps {
+		result.Sleeps = append(result.Sleeps, benchmarkSleep(config, sleep))
+	}
+	return result
+}
+
+type strategyRun struct {
+	loadOrStore LoadOrStoreFunc
+	reads       atomic.Int64
+	duration    time.Duration
+	latencies   histogram
+}
+
+func benchmarkSleep(config BenchmarkConfig, sleep time.Duration) SleepResult {
+	start := time.Now()
+
+	runs := make([]*strategyRun, len(config.Strategies))
+	for i, strategy := range config.Strategies {
+		runs[i] = &strategyRun{loadOrStore: strategy.New()}
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for round := 0; round < config.Rounds; round++ {
+		for _, run := range runs {
+			roundStart := time.Now()
+			for i := 0; i < config.Routines; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					var latencies histogram
+					for j := 0; j < config.Reps; j++ {
+						opStart := time.Now()
+						run.loadOrStore(i, readUUID(&run.reads, sleep))
+						latencies.add(time.Since(opStart))
+					}
+					mu.Lock()
+					run.latencies.merge(&latencies)
+					mu.Unlock()
+				}(i)
+			}
+			wg.Wait()
+			run.duration += time.Since(roundStart)
+		}
+	}
+
+	result := SleepResult{Sleep: sleep, Duration: time.Since(start)}
+	for i, run := range runs {
+		result.Strategies = append(result.Strategies, StrategyResult{
+			Name:       config.Strategies[i].Name,
+			ReadCount:  run.reads.Load(),
+			Duration:   run.duration,
+			P99Latency: run.latencies.quantile(0.99),
+		})
+	}
+	return result
+}
+
+func readUUID(cnt *atomic.Int64, sleep time.Duration) func() string {
+	return func() string {
+		cnt.Add(1)
+		time.Sleep(sleep)
+		return uuid.NewString()
+	}
+}
+
+// histogram counts durations in buckets of powers of two nanoseconds.
+type histogram [65]uint64
+
+func (h *histogram) add(d time.Duration) {
+	h[bits.Len64(uint64(max(d, 0)))]++
+}
+
+func (h *histogram) merge(other *histogram) {
+	for i, n := range other {
+		h[i] += n
+	}
+}
+
+// quantile returns the upper bound of the bucket containing the quantile q.
+func (h *histogram) quantile(q float64) time.Duration {
+	var total uint64
+	for _, n := range h {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := uint64(q * float64(total))
+	var seen uint64
+	for i, n := range h {
+		seen += n
+		if seen > rank && i < 63 {
+			return time.Duration(1) << i
+		}
+	}
+	return time.Duration(1<<63 - 1)
+}
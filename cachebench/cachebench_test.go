@@ -0,0 +1,29 @@
+package cachebench
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReadCounterRace counts the reads of goroutines loading concurrently,
+// run it with -race.
+func TestReadCounterRace(t *testing.T) {
+	config := BenchmarkConfig{
+		Rounds:   2,
+		Routines: 16,
+		Reps:     10,
+		Sleeps:   []time.Duration{0},
+		Strategies: []Strategy{{
+			Name: "uncached",
+			New: func() LoadOrStoreFunc {
+				return func(key int, read func() string) string { return read() }
+			},
+		}},
+	}
+	result := Benchmark(config)
+
+	want := int64(config.Rounds * config.Routines * config.Reps)
+	if got := result.Sleeps[0].Strategies[0].ReadCount; got != want {
+		t.Errorf("counted %d reads, want %d", got, want)
+	}
+}
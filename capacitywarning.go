@@ -0,0 +1,67 @@
+package main
+
+import "time"
+
+// capacityCheckInterval is how often CapacityWarningCache checks the cost of
+// the cache.
+const capacityCheckInterval = time.Second
+
+// WithCapacityWarning calls warn once the cost of the cache reaches the
+// fraction threshold of the maximum cost. It is not called again until the
+// cost drops below 90% of the threshold.
+func WithCapacityWarning[K comparable, T any](threshold float64, warn func()) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.capacityWarning = threshold
+		o.onCapacity = warn
+	}
+}
+
+// CapacityWarningCache checks the cost of the cache every second and warns
+// when it approaches the maximum cost, before ristretto starts evicting.
+type CapacityWarningCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	stop chan struct{}
+}
+
+func NewCacheWithCapacityWarning[K comparable, T any](threshold float64, warn func(), opts ...Option[K, T]) (*CapacityWarningCache[K, T], error) {
+	cache, err := NewCache(append(opts, WithCapacityWarning[K, T](threshold, warn))...)
+	if err != nil {
+		return nil, err
+	}
+	c := &CapacityWarningCache[K, T]{
+		ristrettoCache: cache,
+		stop:           make(chan struct{}),
+	}
+	go c.watch()
+	return c, nil
+}
+
+// Close stops checking the cost and closes the cache.
+func (c *CapacityWarningCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *CapacityWarningCache[K, T]) watch() {
+	ticker := time.NewTicker(capacityCheckInterval)
+	defer ticker.Stop()
+
+	warned := false
+	for {
+		select {
+		case <-ticker.C:
+			// deletes and evictions are both counted as evicted cost
+			metrics := c.cache.Metrics
+			used := float64(metrics.CostAdded()-metrics.CostEvicted()) / float64(c.cache.MaxCost())
+			switch {
+			case !warned && used >= c.capacityWarning:
+				warned = true
+				c.onCapacity()
+			case warned && used < 0.9*c.capacityWarning:
+				warned = false
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapacityWarning(t *testing.T) {
+	warnings := make(chan struct{}, 10)
+	c, err := NewCacheWithCapacityWarning(0.8, func() { warnings <- struct{}{} }, WithMaxCost[int, int](10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	fill := func() {
+		for i := 0; i < 9; i++ {
+			if !c.SetDefault(i, i) {
+				t.Fatalf("set %d dropped", i)
+			}
+		}
+	}
+	waitForWarning := func(want bool) {
+		t.Helper()
+		select {
+		case <-warnings:
+			if !want {
+				t.Fatal("warned again without the cost dropping")
+			}
+		case <-time.After(3 * capacityCheckInterval / 2):
+			if want {
+				t.Fatal("no warning at 90% of the maximum cost")
+			}
+		}
+	}
+
+	fill()
+	waitForWarning(true)
+	waitForWarning(false)
+
+	// dropping below 90% of the threshold arms the warning again
+	for i := 0; i < 5; i++ {
+		c.Delete(i)
+	}
+	time.Sleep(3 * capacityCheckInterval / 2)
+	fill()
+	waitForWarning(true)
+}
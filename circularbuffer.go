@@ -0,0 +1,116 @@
+package main
+
+import "sync"
+
+// CircularBufferCache keeps the values of the last capacity keys stored, a
+// new key overwrites the oldest one. Values do not expire.
+type CircularBufferCache[K comparable, T any] struct {
+	mu    sync.Mutex
+	slots []circularSlot[K, T]
+	// next is the slot the next key is stored in, it holds the oldest key
+	// once the buffer is full
+	next  int
+	full  bool
+	index map[K]int
+
+	locks sync.Map
+}
+
+type circularSlot[K comparable, T any] struct {
+	key   K
+	value T
+}
+
+func NewCacheWithCircularBuffer[K comparable, T any](capacity int) *CircularBufferCache[K, T] {
+	return &CircularBufferCache[K, T]{
+		slots: make([]circularSlot[K, T], capacity),
+		index: make(map[K]int, capacity),
+	}
+}
+
+func (c *CircularBufferCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock := anyLock.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	value := read()
+	c.SetDefault(key, value)
+	return value
+}
+
+func (c *CircularBufferCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i, ok := c.index[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return c.slots[i].value, true
+}
+
+// SetDefault replaces the value of a stored key in place, other keys are
+// stored in the slot of the oldest key.
+func (c *CircularBufferCache[K, T]) SetDefault(key K, value T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.slots) == 0 {
+		return false
+	}
+	if i, ok := c.index[key]; ok {
+		c.slots[i].value = value
+		return true
+	}
+	if old := c.slots[c.next].key; c.full && c.index[old] == c.next {
+		// the old key may have been deleted and stored in another slot
+		delete(c.index, old)
+	}
+	c.slots[c.next] = circularSlot[K, T]{key: key, value: value}
+	c.index[key] = c.next
+	c.next = (c.next + 1) % len(c.slots)
+	c.full = c.full || c.next == 0
+	return true
+}
+
+// Delete removes the key, its slot stays in the FIFO order until it is
+// overwritten.
+func (c *CircularBufferCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.index, key)
+}
+
+// ForEach calls fn for the stored keys from the oldest to the newest until
+// fn returns false. fn must not call other methods of the cache.
+func (c *CircularBufferCache[K, T]) ForEach(fn func(key K, value T) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start, n := 0, c.next
+	if c.full {
+		start, n = c.next, len(c.slots)
+	}
+	for j := 0; j < n; j++ {
+		i := (start + j) % len(c.slots)
+		slot := c.slots[i]
+		if idx, ok := c.index[slot.key]; !ok || idx != i {
+			continue
+		}
+		if !fn(slot.key, slot.value) {
+			return
+		}
+	}
+}
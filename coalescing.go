@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// CacheWithRequestCoalescing shares the result of a reader with all requests
+// for the same key arriving while it runs or within coalescingWindow after it
+// returned, even if the value was not stored or has been evicted since. Such
+// requests may get a value up to coalescingWindow older than a fresh read.
+type CacheWithRequestCoalescing[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	coalescingWindow time.Duration
+	reads            sync.Map
+}
+
+type coalescedRead[T any] struct {
+	done       chan struct{}
+	value      T
+	err        error
+	finishedAt time.Time
+}
+
+func NewCacheWithRequestCoalescing[K comparable, T any](coalescingWindow time.Duration, opts ...Option[K, T]) (*CacheWithRequestCoalescing[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CacheWithRequestCoalescing[K, T]{
+		ristrettoCache:   cache,
+		coalescingWindow: coalescingWindow,
+	}, nil
+}
+
+func (c *CacheWithRequestCoalescing[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *CacheWithRequestCoalescing[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	return c.ristrettoCache.LoadOrStoreE(key, func() (T, error) {
+		return c.coalesce(key, read)
+	})
+}
+
+func (c *CacheWithRequestCoalescing[K, T]) coalesce(key K, read readerE[T]) (T, error) {
+	for {
+		r := &coalescedRead[T]{done: make(chan struct{})}
+		actual, loaded := c.reads.LoadOrStore(key, r)
+		if !loaded {
+			r.value, r.err = read()
+			r.finishedAt = time.Now()
+			close(r.done)
+			time.AfterFunc(c.coalescingWindow, func() {
+				c.reads.CompareAndDelete(key, r)
+			})
+			return r.value, r.err
+		}
+
+		r = actual.(*coalescedRead[T])
+		<-r.done
+		if time.Since(r.finishedAt) <= c.coalescingWindow {
+			return r.value, r.err
+		}
+		// the timer has not removed the read yet
+		c.reads.CompareAndDelete(key, r)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// CollisionDetectingCache verifies that entries returned by ristretto were
+// stored under the requested key. Ristretto identifies keys only by their
+// hashes, so colliding keys would otherwise silently get each other's values.
+// Collisions are logged, counted and treated as misses.
+type CollisionDetectingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	collisions atomic.Uint64
+}
+
+func NewCacheWithHashCollisionDetection[K comparable, T any](opts ...Option[K, T]) (*CollisionDetectingCache[K, T], error) {
+	c := &CollisionDetectingCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onCollision = func(key K, e *entry[K, T]) {
+			c.collisions.Add(1)
+			slog.Warn("cache key hash collision", "key", key, "storedKey", e.key)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *CollisionDetectingCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.Collisions = c.collisions.Load()
+	return stats
+}
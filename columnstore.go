@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ColumnStoreCache stores every field of the struct T in its own ristretto
+// entry, so callers interested in a single field do not have to load the
+// whole struct. T must be a flat struct of exported non-pointer fields.
+// Values are read again when any of their fields has been evicted.
+type ColumnStoreCache[K comparable, T any] struct {
+	fields *ristrettoCache[columnKey[K], any]
+	names  []string
+}
+
+type columnKey[K comparable] struct {
+	key   K
+	field string
+}
+
+func NewCacheWithColumnStore[K comparable, T any]() (*ColumnStoreCache[K, T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("column store needs a struct, got %s", typ)
+	}
+	names := make([]string, typ.NumField())
+	for i := range names {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			return nil, fmt.Errorf("field %s of %s is not exported", field.Name, typ)
+		}
+		if field.Type.Kind() == reflect.Pointer {
+			return nil, fmt.Errorf("field %s of %s is a pointer", field.Name, typ)
+		}
+		names[i] = field.Name
+	}
+
+	fields, err := NewCache[columnKey[K], any]()
+	if err != nil {
+		return nil, err
+	}
+	return &ColumnStoreCache[K, T]{fields: fields, names: names}, nil
+}
+
+func (c *ColumnStoreCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.assemble(key); ok {
+		return value
+	}
+
+	// the key lock of the empty field name guards the whole value
+	lock := c.fields.keyLock(columnKey[K]{key: key})
+	lock.Lock()
+	defer lock.Unlock()
+
+	if value, ok := c.assemble(key); ok {
+		return value
+	}
+
+	value := read()
+	v := reflect.ValueOf(value)
+	for i, name := range c.names {
+		c.fields.set(columnKey[K]{key: key, field: name}, v.Field(i).Interface())
+	}
+	c.fields.cache.Wait()
+	return value
+}
+
+// GetField returns a single field of the cached value.
+func (c *ColumnStoreCache[K, T]) GetField(key K, fieldName string) (any, bool) {
+	return c.fields.Peek(columnKey[K]{key: key, field: fieldName})
+}
+
+func (c *ColumnStoreCache[K, T]) Delete(key K) {
+	for _, name := range c.names {
+		c.fields.Delete(columnKey[K]{key: key, field: name})
+	}
+}
+
+func (c *ColumnStoreCache[K, T]) Close() {
+	c.fields.Close()
+}
+
+// assemble builds the value from its fields, it returns false if any of them
+// is not cached.
+func (c *ColumnStoreCache[K, T]) assemble(key K) (T, bool) {
+	var value T
+	v := reflect.ValueOf(&value).Elem()
+	for i, name := range c.names {
+		field, ok := c.fields.Peek(columnKey[K]{key: key, field: name})
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		if field != nil {
+			// nil fields of interface types are left zero
+			v.Field(i).Set(reflect.ValueOf(field))
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// CompactingCache can move its live entries to a new ristretto instance with
+// Compact, releasing the memory fragmented by entries which left the cache.
+type CompactingCache[K comparable, T any] struct {
+	opts []Option[K, T]
+
+	// mu guards current, writeMu keeps writes from going to the old
+	// instance while Compact copies it
+	mu      sync.RWMutex
+	writeMu sync.RWMutex
+	current *compactedInstance[K, T]
+}
+
+type compactedInstance[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+}
+
+func NewCacheWithCompactedStorage[K comparable, T any](opts ...Option[K, T]) (*CompactingCache[K, T], error) {
+	c := &CompactingCache[K, T]{opts: opts}
+	current, err := c.newInstance(true)
+	if err != nil {
+		return nil, err
+	}
+	c.current = current
+	return c, nil
+}
+
+func (c *CompactingCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	c.writeMu.RLock()
+	defer c.writeMu.RUnlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current.LoadOrStore(key, read)
+}
+
+func (c *CompactingCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current.Peek(key)
+}
+
+func (c *CompactingCache[K, T]) SetDefault(key K, value T) bool {
+	c.writeMu.RLock()
+	defer c.writeMu.RUnlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.current.SetDefault(key, value)
+}
+
+func (c *CompactingCache[K, T]) Delete(key K) {
+	c.writeMu.RLock()
+	defer c.writeMu.RUnlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.current.Delete(key)
+}
+
+// ForEach calls fn for the cached values until fn returns false.
+func (c *CompactingCache[K, T]) ForEach(fn func(key K, value T) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.current.forEach(func(e *entry[K, T]) bool {
+		return fn(e.key, e.value)
+	})
+}
+
+// Compact copies the live entries to a new ristretto instance and swaps it
+// in, keeping their remaining TTLs. Reads are served by the old instance
+// while the entries are copied, writes wait until the swap. If ctx is done
+// before the copy completes, the old instance is kept.
+func (c *CompactingCache[K, T]) Compact(ctx context.Context) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	compacted, err := c.newInstance(false)
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	old := c.current
+	c.mu.RUnlock()
+
+	old.forEach(func(e *entry[K, T]) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		if !compacted.storeWithCost(e, e.cost) {
+			// the set buffer is full, let it drain and try again
+			compacted.cache.Wait()
+			compacted.storeWithCost(e, e.cost)
+		}
+		return true
+	})
+	if err != nil {
+		compacted.Close()
+		return err
+	}
+	compacted.cache.Wait()
+
+	c.mu.Lock()
+	c.current = compacted
+	c.mu.Unlock()
+	old.Close()
+	return nil
+}
+
+func (c *CompactingCache[K, T]) Close() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.current.Close()
+}
+
+// newInstance creates a ristretto instance with the options of the cache,
+// only the first instance runs the preloader.
+func (c *CompactingCache[K, T]) newInstance(preload bool) (*compactedInstance[K, T], error) {
+	instance := &compactedInstance[K, T]{}
+	cache, err := NewCache(append(c.opts, func(o *options[K, T]) {
+		if !preload {
+			o.preloader = nil
+		}
+		o.onSet = func(e *entry[K, T]) {
+			instance.entries.Store(e.key, e)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			instance.entries.CompareAndDelete(e.key, e)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	instance.ristrettoCache = cache
+	return instance, nil
+}
+
+func (i *compactedInstance[K, T]) forEach(fn func(e *entry[K, T]) bool) {
+	i.entries.Range(func(_, value any) bool {
+		return fn(value.(*entry[K, T]))
+	})
+}
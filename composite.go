@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// CompositeCache is keyed by pairs of keys, the pairs are hashed into a
+// single ristretto key. The hashes are tracked by the first part of the key,
+// so all entries sharing it can be deleted at once.
+type CompositeCache[A, B comparable, T any] struct {
+	cache *ristrettoCache[uint64, T]
+	keys  sync.Map
+}
+
+type compositeKeys struct {
+	mu     sync.Mutex
+	hashes map[uint64]struct{}
+}
+
+func NewCacheWithCompositeKey[A, B comparable, T any](opts ...Option[uint64, T]) (*CompositeCache[A, B, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CompositeCache[A, B, T]{cache: cache}, nil
+}
+
+func (c *CompositeCache[A, B, T]) LoadOrStore(a A, b B, read reader[T]) T {
+	hash := compositeHash(a, b)
+	value, _, _ := c.cache.loadOrStoreSource(hash, func() (T, error) {
+		anyKeys, _ := c.keys.LoadOrStore(a, &compositeKeys{hashes: map[uint64]struct{}{}})
+		keys := anyKeys.(*compositeKeys)
+		keys.mu.Lock()
+		keys.hashes[hash] = struct{}{}
+		keys.mu.Unlock()
+		return read(), nil
+	}, read.withError())
+	return value
+}
+
+func (c *CompositeCache[A, B, T]) Peek(a A, b B) (T, bool) {
+	return c.cache.Peek(compositeHash(a, b))
+}
+
+func (c *CompositeCache[A, B, T]) Delete(a A, b B) {
+	c.cache.Delete(compositeHash(a, b))
+}
+
+// DeleteByFirstPart deletes all entries whose key starts with a.
+func (c *CompositeCache[A, B, T]) DeleteByFirstPart(a A) {
+	anyKeys, ok := c.keys.LoadAndDelete(a)
+	if !ok {
+		return
+	}
+	keys := anyKeys.(*compositeKeys)
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	for hash := range keys.hashes {
+		c.cache.Delete(hash)
+	}
+}
+
+func (c *CompositeCache[A, B, T]) Close() {
+	c.cache.Close()
+}
+
+func compositeHash[A, B comparable](a A, b B) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%#v\x00%#v", a, b)
+	return h.Sum64()
+}
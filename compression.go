@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// stored values start with one of these markers
+const (
+	plainValue byte = iota
+	compressedValue
+)
+
+// CompressionCache caches gob encoded values, values encoded to more than
+// minSize bytes are stored zstd compressed. ProtoCached uses it with the
+// wire format of messages instead. The cost of entries is their
+// stored size. LoadOrStore and Peek return the zero value for values which
+// cannot be encoded or decoded, LoadOrStoreE returns the error.
+type CompressionCache[K comparable, T any] struct {
+	cache   *ristrettoCache[K, []byte]
+	minSize int
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	// marshal appends the encoding of the value to b
+	marshal   func(b []byte, value T) ([]byte, error)
+	unmarshal func(data []byte) (T, error)
+
+	encodedBytes atomic.Uint64
+	storedBytes  atomic.Uint64
+}
+
+var _ Cache[string, int] = (*CompressionCache[string, int])(nil)
+
+func NewCacheWithTransparentCompression[K comparable, T any](minSize int, opts ...Option[K, []byte]) (*CompressionCache[K, T], error) {
+	return newCompressionCache(minSize, gobMarshal[T], gobUnmarshal[T], opts...)
+}
+
+func newCompressionCache[K comparable, T any](minSize int, marshal func([]byte, T) ([]byte, error), unmarshal func([]byte) (T, error), opts ...Option[K, []byte]) (*CompressionCache[K, T], error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		encoder.Close()
+		return nil, err
+	}
+	c := &CompressionCache[K, T]{
+		minSize:   minSize,
+		encoder:   encoder,
+		decoder:   decoder,
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, []byte]) {
+		o.costEstimator = func(_ K, value []byte) int64 {
+			return int64(len(value))
+		}
+		o.storeMiss = func(e *entry[K, []byte]) error {
+			c.store(e)
+			c.cache.cache.Wait()
+			return nil
+		}
+		o.onReject = c.uncount
+	})...)
+	if err != nil {
+		encoder.Close()
+		decoder.Close()
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+func (c *CompressionCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE returns the cached value, it returns an error if the value
+// cannot be encoded or decoded.
+func (c *CompressionCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	// audits would compare the encoded values
+	data, _, err := c.cache.loadOrStoreSource(key, func() ([]byte, error) {
+		value, err := read()
+		if err != nil {
+			return nil, err
+		}
+		return c.encode(value)
+	}, nil)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return c.decode(data)
+}
+
+func (c *CompressionCache[K, T]) Peek(key K) (T, bool) {
+	data, ok := c.cache.Peek(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	value, err := c.decode(data)
+	return value, err == nil
+}
+
+// SetDefault reports false if the value cannot be encoded.
+func (c *CompressionCache[K, T]) SetDefault(key K, value T) bool {
+	data, err := c.encode(value)
+	if err != nil {
+		return false
+	}
+	ok := c.store(c.cache.newEntry(key, data))
+	c.cache.cache.Wait()
+	return ok
+}
+
+func (c *CompressionCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *CompressionCache[K, T]) Stats() Stats {
+	stats := c.cache.Stats()
+	if stored := c.storedBytes.Load(); stored > 0 {
+		stats.CompressionRatio = float64(c.encodedBytes.Load()) / float64(stored)
+	}
+	return stats
+}
+
+func (c *CompressionCache[K, T]) Close() {
+	c.cache.Close()
+	c.encoder.Close()
+	c.decoder.Close()
+}
+
+// encode returns the encoding of the value after the plain value marker.
+func (c *CompressionCache[K, T]) encode(value T) ([]byte, error) {
+	return c.marshal([]byte{plainValue}, value)
+}
+
+// store compresses the encoded value of the entry if it is larger than the
+// minimum size and counts its bytes once it is stored.
+func (c *CompressionCache[K, T]) store(e *entry[K, []byte]) bool {
+	encoded := e.value[1:]
+	if len(encoded) > c.minSize {
+		e.value = c.encoder.EncodeAll(encoded, []byte{compressedValue})
+		e.cost = int64(len(e.value))
+	}
+	if !c.cache.setEntry(e) {
+		return false
+	}
+	c.encodedBytes.Add(uint64(len(encoded)))
+	c.storedBytes.Add(uint64(len(e.value) - 1))
+	return true
+}
+
+// uncount takes the bytes of entries rejected by ristretto off the counts.
+func (c *CompressionCache[K, T]) uncount(e *entry[K, []byte]) {
+	encoded := e.value[1:]
+	if e.value[0] == compressedValue {
+		var err error
+		if encoded, err = c.decoder.DecodeAll(encoded, nil); err != nil {
+			return
+		}
+	}
+	c.encodedBytes.Add(^uint64(len(encoded) - 1))
+	c.storedBytes.Add(^uint64(len(e.value) - 2))
+}
+
+func (c *CompressionCache[K, T]) decode(data []byte) (T, error) {
+	var value T
+	if len(data) == 0 {
+		return value, errors.New("empty cached value")
+	}
+	encoded := data[1:]
+	if data[0] == compressedValue {
+		var err error
+		if encoded, err = c.decoder.DecodeAll(encoded, nil); err != nil {
+			return value, err
+		}
+	}
+	return c.unmarshal(encoded)
+}
+
+func gobMarshal[T any](b []byte, value T) ([]byte, error) {
+	buf := bytes.NewBuffer(b)
+	if err := gob.NewEncoder(buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobUnmarshal[T any](data []byte) (T, error) {
+	var value T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestCompressionRoundTripsJSON(t *testing.T) {
+	c, err := NewCacheWithTransparentCompression[string, string](1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	type item struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	items := make([]item, 200)
+	for i := range items {
+		items[i] = item{ID: i, Name: "user " + strconv.Itoa(i), Email: "user" + strconv.Itoa(i) + "@example.com"}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < 10_000 {
+		t.Fatalf("test value has %d bytes, want 10KB", len(data))
+	}
+	want := string(data)
+
+	if got, err := c.LoadOrStoreE("k", func() (string, error) { return want, nil }); err != nil || got != want {
+		t.Fatalf("LoadOrStoreE returned %d bytes, %v, want the original %d bytes", len(got), err, len(want))
+	}
+	e, ok := c.cache.get("k")
+	if !ok || e.value[0] != compressedValue || len(e.value) >= len(want) {
+		t.Fatalf("10KB value not stored compressed")
+	}
+	got, ok := c.Peek("k")
+	if !ok || got != want {
+		t.Errorf("Peek returned %d bytes, %v, want the original %d bytes", len(got), ok, len(want))
+	}
+	if ratio := c.Stats().CompressionRatio; ratio <= 1 {
+		t.Errorf("compression ratio %.2f", ratio)
+	}
+}
+
+func TestCompressionRatioSkipsRejectedValues(t *testing.T) {
+	c, err := NewCacheWithTransparentCompression[string, string](0, WithMaxCost[string, []byte](10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the compressed value costs more than the whole cache
+	random := make([]byte, 1000)
+	for i := range random {
+		random[i] = byte(i * 7919 >> 3)
+	}
+	c.SetDefault("k", string(random))
+	if _, ok := c.Peek("k"); ok {
+		t.Fatal("value larger than the cache stored")
+	}
+	if ratio := c.Stats().CompressionRatio; ratio != 0 {
+		t.Errorf("compression ratio %.2f counts the rejected value", ratio)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "context"
+
+// ConcurrencyLimitedCache allows at most maxConcurrency LoadOrStore calls at
+// a time, hits included. Further callers block until a call returns or
+// their context is done.
+type ConcurrencyLimitedCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	slots chan struct{}
+}
+
+func NewCacheWithConcurrentLimit[K comparable, T any](maxConcurrency int, opts ...Option[K, T]) (*ConcurrencyLimitedCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConcurrencyLimitedCache[K, T]{
+		ristrettoCache: cache,
+		slots:          make(chan struct{}, maxConcurrency),
+	}, nil
+}
+
+// LoadOrStoreE returns ctx.Err() if ctx is done before the call could start.
+func (c *ConcurrencyLimitedCache[K, T]) LoadOrStoreE(ctx context.Context, key K, read readerE[T]) (T, error) {
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+	defer func() { <-c.slots }()
+
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *ConcurrencyLimitedCache[K, T]) LoadOrStore(ctx context.Context, key K, read reader[T]) (T, error) {
+	return c.LoadOrStoreE(ctx, key, read.withError())
+}
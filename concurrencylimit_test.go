@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// benchmarkInFlightReads runs 256 goroutines loading distinct keys whose
+// readers each hold a 64KB buffer, and reports the peak memory held by the
+// readers running at the same time.
+func benchmarkInFlightReads(b *testing.B, load func(key int, read reader[[]byte])) {
+	var inFlight, peak atomic.Int64
+	read := func() []byte {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		buf := make([]byte, 64<<10)
+		time.Sleep(time.Millisecond)
+		return buf[:1]
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for g := 0; g < 256; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := int(next.Add(1)); key <= b.N; key = int(next.Add(1)) {
+				load(key, read)
+			}
+		}()
+	}
+	wg.Wait()
+	b.ReportMetric(float64(peak.Load()*64), "peak-KB")
+}
+
+func BenchmarkConcurrencyLimit(b *testing.B) {
+	b.Run("unlimited", func(b *testing.B) {
+		c, err := NewCache[int, []byte]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkInFlightReads(b, func(key int, read reader[[]byte]) { c.LoadOrStore(key, read) })
+	})
+	b.Run("limit=16", func(b *testing.B) {
+		c, err := NewCacheWithConcurrentLimit[int, []byte](16)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkInFlightReads(b, func(key int, read reader[[]byte]) { c.LoadOrStore(context.Background(), key, read) })
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+// PeerClient loads keys from the caches of other nodes.
+type PeerClient[K comparable, T any] interface {
+	LoadOrStore(node string, key K) (T, error)
+}
+
+// ConsistentHashCache spreads keys over several nodes with consistent
+// hashing. Keys owned by this node are cached locally, other keys are loaded
+// from their owner through the peer client.
+type ConsistentHashCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	ring  *hashRing
+	self  string
+	peers PeerClient[K, T]
+}
+
+func NewCacheWithConsistentHashing[K comparable, T any](self string, nodes []string, peers PeerClient[K, T], opts ...Option[K, T]) (*ConsistentHashCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsistentHashCache[K, T]{
+		ristrettoCache: cache,
+		ring:           newHashRing(append(nodes, self)),
+		self:           self,
+		peers:          peers,
+	}, nil
+}
+
+// Owner returns the node owning the key.
+func (c *ConsistentHashCache[K, T]) Owner(key K) string {
+	return c.ring.node(key)
+}
+
+func (c *ConsistentHashCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE loads the key locally if this node owns it, read is not used
+// for keys owned by other nodes.
+func (c *ConsistentHashCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if owner := c.ring.node(key); owner != c.self {
+		return c.peers.LoadOrStore(owner, key)
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
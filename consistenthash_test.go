@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+type fakePeers struct {
+	loads map[string]int
+}
+
+func (p *fakePeers) LoadOrStore(node string, key string) (string, error) {
+	p.loads[node]++
+	return node + ":" + key, nil
+}
+
+func TestConsistentHashRoutesKeysToTheirOwner(t *testing.T) {
+	peers := &fakePeers{loads: map[string]int{}}
+	c, err := NewCacheWithConsistentHashing[string, string]("self", []string{"a", "b"}, peers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		value := c.LoadOrStore(key, func() string { return "local" })
+		if owner := c.Owner(key); owner == "self" {
+			if value != "local" {
+				t.Errorf("key %s owned by this node returned %q", key, value)
+			}
+		} else if value != owner+":"+key {
+			t.Errorf("key %s owned by %s returned %q", key, owner, value)
+		}
+	}
+	if peers.loads["a"] == 0 || peers.loads["b"] == 0 {
+		t.Errorf("no keys loaded from peers: %v", peers.loads)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ContentAddressedCache stores immutable values under the SHA-256 hash of
+// their JSON encoding, so equal values always share an entry. The JSON
+// encoding is deterministic, map keys are sorted, as long as the values do
+// not implement json.Marshaler differently. Values differing only in
+// unexported fields share an entry too.
+type ContentAddressedCache[T any] struct {
+	cache *ristrettoCache[string, T]
+}
+
+func NewCacheWithContentAddressedKeys[T any](opts ...Option[string, T]) (*ContentAddressedCache[T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentAddressedCache[T]{cache: cache}, nil
+}
+
+// Store caches the value and returns its hex encoded hash.
+func (c *ContentAddressedCache[T]) Store(value T) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if _, ok := c.cache.Peek(hash); !ok {
+		c.cache.SetDefault(hash, value)
+	}
+	return hash, nil
+}
+
+// Retrieve returns the value stored under the hash.
+func (c *ContentAddressedCache[T]) Retrieve(hash string) (T, bool) {
+	return c.cache.Peek(hash)
+}
+
+func (c *ContentAddressedCache[T]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestContentAddressedEqualMapsShareAKey(t *testing.T) {
+	c, err := NewCacheWithContentAddressedKeys[map[string]int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	value := map[string]int{}
+	for i := 0; i < 100; i++ {
+		value[strconv.Itoa(i)] = i
+	}
+	first, err := c.Store(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// map iteration order differs between runs, the hash must not
+	for i := 0; i < 10; i++ {
+		hash, err := c.Store(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash != first {
+			t.Fatalf("equal maps hashed to %s and %s", first, hash)
+		}
+	}
+	if stored, ok := c.Retrieve(first); !ok || len(stored) != len(value) {
+		t.Errorf("Retrieve() = %v, %v", stored, ok)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import "context"
+
+type readerCtx[T any] func(ctx context.Context) (T, context.Context)
+
+type contextValue[T any] struct {
+	value  T
+	values map[any]any
+}
+
+type contextValueOptions struct {
+	keys []any
+}
+
+type ContextValueOption func(*contextValueOptions)
+
+// WithContextKeys sets the keys of the context values stored with the cached
+// values.
+func WithContextKeys(keys ...any) ContextValueOption {
+	return func(o *contextValueOptions) {
+		o.keys = append(o.keys, keys...)
+	}
+}
+
+// ContextValueCache stores selected context values along with the cached
+// values and adds them to the context of callers hitting the cache, e.g. the
+// trace ID of the request which read the value.
+type ContextValueCache[K comparable, T any] struct {
+	cache *ristrettoCache[K, contextValue[T]]
+	contextValueOptions
+}
+
+func NewCacheWithContextValue[K comparable, T any](opts ...ContextValueOption) (*ContextValueCache[K, T], error) {
+	cache, err := NewCache[K, contextValue[T]]()
+	if err != nil {
+		return nil, err
+	}
+	c := &ContextValueCache[K, T]{cache: cache}
+	for _, opt := range opts {
+		opt(&c.contextValueOptions)
+	}
+	return c, nil
+}
+
+// LoadOrStoreWithContext returns the value and a context with the values
+// stored with it. On a miss the context returned by the reader is used to
+// pick the values to store and it is returned as it is.
+func (c *ContextValueCache[K, T]) LoadOrStoreWithContext(ctx context.Context, key K, read readerCtx[T]) (T, context.Context) {
+	var readCtx context.Context
+	cv, _, _ := c.cache.loadOrStoreSource(key, func() (contextValue[T], error) {
+		value, valueCtx := read(ctx)
+		readCtx = valueCtx
+		values := map[any]any{}
+		for _, k := range c.keys {
+			if v := valueCtx.Value(k); v != nil {
+				values[k] = v
+			}
+		}
+		return contextValue[T]{value: value, values: values}, nil
+	}, nil)
+	if readCtx != nil {
+		return cv.value, readCtx
+	}
+
+	for k, v := range cv.values {
+		ctx = context.WithValue(ctx, k, v)
+	}
+	return cv.value, ctx
+}
+
+func (c *ContextValueCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *ContextValueCache[K, T]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCoolingDown = errors.New("key is cooling down after a failed read")
+
+// CacheWithCooldown does not retry reading a key for the cooldown duration
+// after its reader failed, callers get ErrCoolingDown instead.
+type CacheWithCooldown[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	cooldown time.Duration
+	cooling  sync.Map
+}
+
+func NewCacheWithCooldown[K comparable, T any](cooldown time.Duration, opts ...Option[K, T]) (*CacheWithCooldown[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CacheWithCooldown[K, T]{
+		ristrettoCache: cache,
+		cooldown:       cooldown,
+	}, nil
+}
+
+func (c *CacheWithCooldown[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *CacheWithCooldown[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		if until, ok := c.cooling.Load(key); ok && time.Now().Before(until.(time.Time)) {
+			var zero T
+			return zero, ErrCoolingDown
+		}
+
+		value, err := read()
+		if err != nil {
+			c.cooling.Store(key, time.Now().Add(c.cooldown))
+			return value, err
+		}
+		c.cooling.Delete(key)
+		return value, nil
+	}, read)
+	return value, err
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCooldownAppliesToLoadOrStore(t *testing.T) {
+	c, err := NewCacheWithCooldown[string, int](time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	failure := errors.New("source unavailable")
+	if _, err := c.LoadOrStoreE("k", func() (int, error) { return 0, failure }); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	value := c.LoadOrStore("k", func() int {
+		t.Error("key read while cooling down")
+		return 1
+	})
+	if value != 0 {
+		t.Errorf("got %d, want the zero value", value)
+	}
+	if _, err := c.LoadOrStoreE("k", func() (int, error) { return 1, nil }); !errors.Is(err, ErrCoolingDown) {
+		t.Errorf("got %v, want %v", err, ErrCoolingDown)
+	}
+}
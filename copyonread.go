@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Copier returns deep copies of values.
+type Copier[T any] interface {
+	Copy(value T) T
+}
+
+// GobCopier copies values by encoding and decoding them with encoding/gob. It
+// panics if T cannot be encoded with gob.
+type GobCopier[T any] struct{}
+
+func (GobCopier[T]) Copy(value T) T {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		panic(err)
+	}
+	var copied T
+	if err := gob.NewDecoder(&buf).Decode(&copied); err != nil {
+		panic(err)
+	}
+	return copied
+}
+
+// WithCopier sets the copier used by CopyOnReadCache, GobCopier is used by
+// default.
+func WithCopier[K comparable, T any](copier Copier[T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.copier = copier
+	}
+}
+
+// CopyOnReadCache returns copies of the cached values, so callers cannot
+// modify the values stored in the cache.
+type CopyOnReadCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCopyOnReadCache[K comparable, T any](opts ...Option[K, T]) (*CopyOnReadCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{WithCopier[K, T](GobCopier[T]{})}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &CopyOnReadCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *CopyOnReadCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.copier.Copy(c.ristrettoCache.LoadOrStore(key, read))
+}
+
+func (c *CopyOnReadCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, err := c.ristrettoCache.LoadOrStoreE(key, read)
+	if err != nil {
+		return value, err
+	}
+	return c.copier.Copy(value), nil
+}
+
+func (c *CopyOnReadCache[K, T]) Peek(key K) (T, bool) {
+	value, ok := c.ristrettoCache.Peek(key)
+	if !ok {
+		return value, false
+	}
+	return c.copier.Copy(value), true
+}
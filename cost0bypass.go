@@ -0,0 +1,54 @@
+package main
+
+// WithCost0Bypass stores entries of cost 0, e.g. session tokens whose Sizer
+// returns 0, in a map next to ristretto, so they are never evicted or
+// rejected by its admission policy. They are only removed once they expire
+// or are deleted. Expired entries are removed when they are next looked up.
+func WithCost0Bypass[K comparable, T any](bypass bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.cost0Bypass = bypass
+	}
+}
+
+func NewCacheWithCost0Bypass[K comparable, T any](opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithCost0Bypass[K, T](true))...)
+}
+
+func (c *ristrettoCache[K, T]) storeBypass(e *entry[K, T]) bool {
+	// the key may have been stored in ristretto with another cost before
+	c.cache.Del(e.key)
+	if prev, ok := c.bypass.Swap(e.key, e); ok && c.onExit != nil {
+		c.onExit(prev.(*entry[K, T]))
+	}
+	if c.bloom != nil {
+		c.bloom.add(e.key)
+	}
+	if c.onSet != nil {
+		c.onSet(e)
+	}
+	return true
+}
+
+func (c *ristrettoCache[K, T]) loadBypass(key K) (*entry[K, T], bool) {
+	val, ok := c.bypass.Load(key)
+	if !ok {
+		return nil, false
+	}
+	e := val.(*entry[K, T])
+	if !e.expired(c.staleTTL) {
+		return e, true
+	}
+	if c.bypass.CompareAndDelete(key, e) {
+		c.evict(e)
+		if c.onExit != nil {
+			c.onExit(e)
+		}
+	}
+	return nil, false
+}
+
+func (c *ristrettoCache[K, T]) deleteBypass(key K) {
+	if prev, ok := c.bypass.LoadAndDelete(key); ok && c.onExit != nil {
+		c.onExit(prev.(*entry[K, T]))
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCost0BypassEntriesAreNotEvicted(t *testing.T) {
+	c, err := NewCacheWithCost0Bypass(
+		WithMaxCost[string, string](10),
+		// session tokens cost nothing, all other values 1
+		WithSizer[string](SizerFunc[string](func(value string) int64 {
+			if strings.HasPrefix(value, "token:") {
+				return 0
+			}
+			return 1
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		c.SetDefault("session:"+strconv.Itoa(i), "token:"+strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		c.SetDefault("page:"+strconv.Itoa(i), "page")
+	}
+	for i := 0; i < 5; i++ {
+		if value, ok := c.Peek("session:" + strconv.Itoa(i)); !ok || value != "token:"+strconv.Itoa(i) {
+			t.Errorf("session %d evicted", i)
+		}
+	}
+
+	c.Delete("session:0")
+	if _, ok := c.Peek("session:0"); ok {
+		t.Error("deleted session kept")
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+// costDecayHits is the number of hits after which the cost of an entry is
+// decayed.
+const costDecayHits = 100
+
+// WithDynamicCostDecay multiplies the cost of entries by decayFactor every
+// 100 hits, the cost never drops below 1. Frequently hit entries become
+// cheap to keep, so ristretto evicts cold entries first regardless of their
+// size. Every decay stores the entry again.
+func WithDynamicCostDecay[K comparable, T any](decayFactor float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.costDecay = decayFactor
+	}
+}
+
+// NewCacheWithNuancedEvictionCost creates a cache decaying the cost of hot
+// entries, by the factor 0.9 unless set with WithDynamicCostDecay.
+func NewCacheWithNuancedEvictionCost[K comparable, T any](opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append([]Option[K, T]{WithDynamicCostDecay[K, T](0.9)}, opts...)...)
+}
+
+// decayCost returns the entry with the decayed cost.
+func (c *ristrettoCache[K, T]) decayCost(e *entry[K, T]) *entry[K, T] {
+	cost := max(int64(float64(e.cost)*c.costDecay), 1)
+	if cost == e.cost || !c.storeWithCost(e, cost) {
+		return e
+	}
+	decayed := *e
+	decayed.cost = cost
+	return &decayed
+}
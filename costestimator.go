@@ -0,0 +1,13 @@
+package main
+
+// WithCostEstimator sets the cost of entries to the result of fn, it is
+// called once for every stored value and takes precedence over the sizer.
+func WithCostEstimator[K comparable, T any](fn func(key K, value T) int64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.costEstimator = fn
+	}
+}
+
+func NewCacheWithCostEstimator[K comparable, T any](estimator func(key K, value T) int64, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithCostEstimator(estimator))...)
+}
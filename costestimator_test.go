@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+// BenchmarkCostEstimatorHitRatio compares caches of the same memory budget
+// holding values whose sizes differ by key, with costs of 1 and with costs
+// estimated from the sizes.
+func BenchmarkCostEstimatorHitRatio(b *testing.B) {
+	const keySpace, budget = 10000, 50000
+	// the value of a key stands for between 1 and 100 bytes
+	size := func(key int) int64 { return 1 + int64(key*7919%100) }
+	keys := benchmarkKeys(keySpace, true)
+
+	b.Run("cost=1", func(b *testing.B) {
+		// as many entries as the budget holds values of the average size
+		c, err := NewCache(WithMaxCost[int, int](budget / 50))
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkLoads(b, c, keys)
+	})
+	b.Run("estimated", func(b *testing.B) {
+		c, err := NewCacheWithCostEstimator(func(key, _ int) int64 { return size(key) }, WithMaxCost[int, int](budget))
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkLoads(b, c, keys)
+	})
+}
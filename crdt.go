@@ -0,0 +1,38 @@
+package main
+
+// CRDT is a value which can be merged with other replicas of itself. Merge
+// must be commutative, associative and idempotent, so replicas merged in any
+// order converge to the same value.
+type CRDT[T any] interface {
+	Merge(other T) T
+}
+
+// CRDTCache merges updates into the cached values instead of replacing them,
+// so several writers can update a key without coordinating. Once an entry
+// expires or is evicted, merging starts over from the next update.
+type CRDTCache[K comparable, T CRDT[T]] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithCRDTValue[K comparable, T CRDT[T]](opts ...Option[K, T]) (*CRDTCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CRDTCache[K, T]{ristrettoCache: cache}, nil
+}
+
+// Merge merges delta into the cached value of the key, or stores it if the
+// key is not cached, and returns the merged value.
+func (c *CRDTCache[K, T]) Merge(key K, delta T) T {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	merged := delta
+	if e, ok := c.get(key); ok {
+		merged = e.value.Merge(delta)
+	}
+	c.SetDefault(key, merged)
+	return merged
+}
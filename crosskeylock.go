@@ -0,0 +1,25 @@
+package main
+
+// CrossKeyLockingCache can lock several keys at once for updates spanning
+// them, e.g. moving a value from one key to another. The locks are the ones
+// LoadOrStore holds while reading a missed key, so no value of a locked key
+// is read meanwhile.
+type CrossKeyLockingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithCrossKeyLocking[K comparable, T any](opts ...Option[K, T]) (*CrossKeyLockingCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CrossKeyLockingCache[K, T]{ristrettoCache: cache}, nil
+}
+
+// MultiLock locks all keys in a stable order, so concurrent MultiLock calls
+// with overlapping keys cannot deadlock, and returns the function unlocking
+// them. While holding the locks, use Peek, SetDefault and Delete for the
+// locked keys, LoadOrStore would wait for the locks on a miss.
+func (c *CrossKeyLockingCache[K, T]) MultiLock(keys []K) (unlock func()) {
+	return c.lockKeys(keys)
+}
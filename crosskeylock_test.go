@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMultiLockWithoutDeadlocks(t *testing.T) {
+	c, err := NewCacheWithCrossKeyLocking[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	keys := []string{"a", "b", "c", "d"}
+	for _, key := range keys {
+		c.SetDefault(key, 100)
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for i := 0; i < 200; i++ {
+				// transfer between two keys passed in random order
+				perm := r.Perm(len(keys))
+				from, to := keys[perm[0]], keys[perm[1]]
+				unlock := c.MultiLock([]string{from, to})
+				a, _ := c.Peek(from)
+				b, _ := c.Peek(to)
+				c.SetDefault(from, a-1)
+				c.SetDefault(to, b+1)
+				unlock()
+			}
+		}(int64(g))
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("multi locks deadlocked")
+	}
+
+	total := 0
+	for _, key := range keys {
+		value, _ := c.Peek(key)
+		total += value
+	}
+	if total != 400 {
+		t.Errorf("transfers left a total of %d, want 400", total)
+	}
+}
+
+func TestMultiLockOrdersKeys(t *testing.T) {
+	c, err := NewCacheWithCrossKeyLocking[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	b := c.keyLock("b")
+	b.Lock()
+	locked := make(chan struct{})
+	go func() {
+		c.MultiLock([]string{"b", "a"})()
+		close(locked)
+	}()
+
+	// a comes first, so it is locked while MultiLock waits for b
+	deadline := time.Now().Add(5 * time.Second)
+	for c.keyLock("a").TryLock() {
+		c.keyLock("a").Unlock()
+		if time.Now().After(deadline) {
+			t.Fatal("a not locked before b")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	b.Unlock()
+	<-locked
+}
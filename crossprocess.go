@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// CrossProcessSyncCache shares invalidations with caches of other processes
+// on the same host over Unix domain sockets. Peers exchange newline separated
+// "DELETE key" and "UPDATED key" messages, so keys must not contain newlines.
+// A received DELETE deletes the key, a received UPDATED reads the key again
+// in the background with the reader it was last stored with.
+type CrossProcessSyncCache[K ~string, T any] struct {
+	*ristrettoCache[K, T]
+	listener net.Listener
+	// readers holds the reader each cached key was stored with, pending the
+	// readers of misses in progress, which hold the key lock
+	readers sync.Map
+	pending sync.Map
+
+	mu    sync.Mutex
+	peers map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+func NewCacheWithCrossProcessSync[K ~string, T any](socket string, opts ...Option[K, T]) (*CrossProcessSyncCache[K, T], error) {
+	c := &CrossProcessSyncCache[K, T]{peers: make(map[net.Conn]struct{})}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		onSet, onExit := o.onSet, o.onExit
+		o.onSet = func(e *entry[K, T]) {
+			if read, ok := c.pending.Load(e.key); ok {
+				c.readers.Store(e.key, *read.(*readerE[T]))
+			}
+			if onSet != nil {
+				onSet(e)
+			}
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// updates call onExit with the replaced entry
+			if _, ok := c.stored(e.key); !ok {
+				c.readers.Delete(e.key)
+			}
+			if onExit != nil {
+				onExit(e)
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		cache.Close()
+		return nil, err
+	}
+	c.listener = listener
+	c.wg.Add(1)
+	go c.accept()
+	return c, nil
+}
+
+func (c *CrossProcessSyncCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *CrossProcessSyncCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	p := &read
+	c.pending.Store(key, p)
+	defer c.pending.CompareAndDelete(key, p)
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+// Connect connects to the cache of another process listening on socket.
+func (c *CrossProcessSyncCache[K, T]) Connect(socket string) error {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	c.serve(conn)
+	return nil
+}
+
+// Delete deletes the key and broadcasts the deletion to all peers.
+func (c *CrossProcessSyncCache[K, T]) Delete(key K) {
+	c.ristrettoCache.Delete(key)
+	c.readers.Delete(key)
+	c.broadcast("DELETE " + string(key) + "\n")
+}
+
+// NotifyUpdated tells all peers the value of the key changed at its source,
+// so they read it again.
+func (c *CrossProcessSyncCache[K, T]) NotifyUpdated(key K) {
+	c.broadcast("UPDATED " + string(key) + "\n")
+}
+
+// Close stops listening, disconnects all peers and closes the cache.
+func (c *CrossProcessSyncCache[K, T]) Close() {
+	c.listener.Close()
+	c.mu.Lock()
+	for conn := range c.peers {
+		conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+	c.ristrettoCache.Close()
+}
+
+func (c *CrossProcessSyncCache[K, T]) accept() {
+	defer c.wg.Done()
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return
+		}
+		c.serve(conn)
+	}
+}
+
+// serve registers the connection as a peer and handles its messages until
+// it is closed.
+func (c *CrossProcessSyncCache[K, T]) serve(conn net.Conn) {
+	c.mu.Lock()
+	c.peers[conn] = struct{}{}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.disconnect(conn)
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			c.handle(scanner.Text())
+		}
+	}()
+}
+
+func (c *CrossProcessSyncCache[K, T]) handle(message string) {
+	command, key, ok := strings.Cut(message, " ")
+	if !ok {
+		return
+	}
+	switch command {
+	case "DELETE":
+		// deletes received from peers are not broadcast again
+		c.ristrettoCache.Delete(K(key))
+		c.readers.Delete(K(key))
+	case "UPDATED":
+		read, ok := c.readers.Load(K(key))
+		if !ok {
+			return
+		}
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.refresh(K(key), read.(readerE[T]))
+		}()
+	}
+}
+
+func (c *CrossProcessSyncCache[K, T]) refresh(key K, read readerE[T]) {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	value, err := c.invoke(read)
+	if err != nil {
+		// keep serving the cached value, it is read again once it expires
+		return
+	}
+	c.SetDefault(key, value)
+}
+
+func (c *CrossProcessSyncCache[K, T]) broadcast(message string) {
+	// a slow peer must not block connecting and disconnecting the others
+	c.mu.Lock()
+	peers := make([]net.Conn, 0, len(c.peers))
+	for conn := range c.peers {
+		peers = append(peers, conn)
+	}
+	c.mu.Unlock()
+
+	for _, conn := range peers {
+		if _, err := io.WriteString(conn, message); err != nil {
+			// serve drops the peer once its connection is closed
+			conn.Close()
+		}
+	}
+}
+
+func (c *CrossProcessSyncCache[K, T]) disconnect(conn net.Conn) {
+	conn.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.peers, conn)
+}
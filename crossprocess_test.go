@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCrossProcessPair(t *testing.T) (*CrossProcessSyncCache[string, string], *CrossProcessSyncCache[string, string]) {
+	dir := t.TempDir()
+	a, err := NewCacheWithCrossProcessSync[string, string](filepath.Join(dir, "a.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(a.Close)
+	b, err := NewCacheWithCrossProcessSync[string, string](filepath.Join(dir, "b.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+
+	// connect the two "processes" in memory instead of over their sockets
+	connA, connB := net.Pipe()
+	a.serve(connA)
+	b.serve(connB)
+	return a, b
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCrossProcessSyncDelete(t *testing.T) {
+	a, b := newCrossProcessPair(t)
+	a.LoadOrStore("k", func() string { return "a" })
+	b.LoadOrStore("k", func() string { return "b" })
+
+	a.Delete("k")
+	waitFor(t, "the peer to delete the key", func() bool {
+		_, ok := b.Peek("k")
+		return !ok
+	})
+	if _, ok := a.Peek("k"); ok {
+		t.Error("key not deleted locally")
+	}
+}
+
+func TestCrossProcessSyncUpdated(t *testing.T) {
+	a, b := newCrossProcessPair(t)
+	var version atomic.Int32
+	b.LoadOrStore("k", func() string {
+		if version.Add(1) == 1 {
+			return "v1"
+		}
+		return "v2"
+	})
+
+	a.NotifyUpdated("k")
+	waitFor(t, "the peer to read the key again", func() bool {
+		value, _ := b.Peek("k")
+		return value == "v2"
+	})
+}
+
+func TestCrossProcessSyncUpdatedUnknownKey(t *testing.T) {
+	a, b := newCrossProcessPair(t)
+	b.SetDefault("k", "v")
+
+	// b has no reader for the key, so it keeps the value
+	a.NotifyUpdated("k")
+	time.Sleep(10 * time.Millisecond)
+	if value, _ := b.Peek("k"); value != "v" {
+		t.Errorf("got %q, want %q", value, "v")
+	}
+}
+
+func TestCrossProcessSyncKeepsNoReadersOfRejectedKeys(t *testing.T) {
+	var exits atomic.Int32
+	c, err := NewCacheWithCrossProcessSync(filepath.Join(t.TempDir(), "c.sock"),
+		WithAllowIf(func(key, _ string) bool { return key != "rejected" }),
+		func(o *options[string, string]) {
+			o.onExit = func(*entry[string, string]) { exits.Add(1) }
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.LoadOrStore("rejected", func() string { return "v" })
+	if _, ok := c.readers.Load("rejected"); ok {
+		t.Error("reader kept for a key which is not stored")
+	}
+	c.LoadOrStore("k", func() string { return "v" })
+	if _, ok := c.readers.Load("k"); !ok {
+		t.Error("reader of a stored key not kept")
+	}
+
+	c.Delete("k")
+	c.cache.Wait()
+	if exits.Load() != 1 {
+		t.Errorf("onExit of the caller called %d times, want 1", exits.Load())
+	}
+	if _, ok := c.readers.Load("k"); ok {
+		t.Error("reader of a deleted key kept")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSetDropped = errors.New("set dropped because the set buffer is full")
+	ErrRejected   = errors.New("rejected by the admission policy")
+)
+
+// FailedEntry is a value ristretto did not store.
+type FailedEntry[K comparable, T any] struct {
+	Key       K
+	Value     T
+	Reason    error
+	Timestamp time.Time
+}
+
+// WithDeadLetterQueue sends values ristretto dropped or rejected to dlq,
+// e.g. to retry or log them. Failed entries are dropped and counted in
+// Stats.DLQDropped when dlq is full. Rejections are reported from
+// ristretto's goroutine.
+func WithDeadLetterQueue[K comparable, T any](dlq chan<- FailedEntry[K, T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.deadLetters = dlq
+	}
+}
+
+func NewCacheWithDeadLetterQueue[K comparable, T any](dlq chan<- FailedEntry[K, T], opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithDeadLetterQueue(dlq))...)
+}
+
+func (c *ristrettoCache[K, T]) deadLetter(e *entry[K, T], reason error) {
+	select {
+	case c.deadLetters <- FailedEntry[K, T]{Key: e.key, Value: e.value, Reason: reason, Timestamp: time.Now()}:
+	default:
+		c.dlqDropped.Add(1)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "sync"
+
+// DedupingCache stores values which are equal to a value already cached under
+// a different key only once, both keys then share the same pointer. Values
+// are looked up by their hash and compared with eq.
+type DedupingCache[K comparable, T any] struct {
+	cache     *ristrettoCache[K, *T]
+	hash      func(value T) uint64
+	eq        func(a, b T) bool
+	canonical sync.Map
+}
+
+func NewDedupingCache[K comparable, T any](hash func(value T) uint64, eq func(a, b T) bool) (*DedupingCache[K, T], error) {
+	c := &DedupingCache[K, T]{
+		hash: hash,
+		eq:   eq,
+	}
+	cache, err := NewCache[K, *T](func(o *options[K, *T]) {
+		o.onExit = func(e *entry[K, *T]) {
+			c.canonical.CompareAndDelete(c.hash(*e.value), e.key)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+func (c *DedupingCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	ptr, _, _ := c.cache.loadOrStoreSource(key, func() (*T, error) {
+		value := read()
+		hash := c.hash(value)
+		if canonical, ok := c.canonical.Load(hash); ok {
+			if ptr, ok := c.cache.Peek(canonical.(K)); ok && c.eq(*ptr, value) {
+				return ptr, nil
+			}
+		}
+		c.canonical.Store(hash, key)
+		return &value, nil
+	}, nil)
+	return *ptr
+}
+
+func (c *DedupingCache[K, T]) Peek(key K) (T, bool) {
+	ptr, ok := c.cache.Peek(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return *ptr, true
+}
+
+func (c *DedupingCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *DedupingCache[K, T]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// AlertFunc is called with the reason when the primary cache is disabled.
+type AlertFunc func(err error)
+
+// GracefulDegradationCache serves operations from the fallback cache when the
+// ristretto cache panics. After maxPanics panics the ristretto cache is
+// disabled and all operations go to the fallback. Panics of readers are not
+// recovered.
+type GracefulDegradationCache[K comparable, T any] struct {
+	primary   *ristrettoCache[K, T]
+	fallback  Cache[K, T]
+	maxPanics int64
+	alert     AlertFunc
+
+	panics   atomic.Int64
+	disabled atomic.Bool
+}
+
+func NewCacheWithGracefulDegradation[K comparable, T any](fallback Cache[K, T], maxPanics int, alert AlertFunc, opts ...Option[K, T]) (*GracefulDegradationCache[K, T], error) {
+	primary, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &GracefulDegradationCache[K, T]{
+		primary:   primary,
+		fallback:  fallback,
+		maxPanics: int64(maxPanics),
+		alert:     alert,
+	}, nil
+}
+
+func (c *GracefulDegradationCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	var readerPanic any
+	guarded := func() T {
+		defer func() {
+			if r := recover(); r != nil {
+				readerPanic = r
+				panic(r)
+			}
+		}()
+		return read()
+	}
+
+	var value T
+	ok := c.protect(func() {
+		value = c.primary.LoadOrStore(key, guarded)
+	}, func() bool { return readerPanic != nil })
+	if readerPanic != nil {
+		panic(readerPanic)
+	}
+	if !ok {
+		return c.fallback.LoadOrStore(key, read)
+	}
+	return value
+}
+
+func (c *GracefulDegradationCache[K, T]) Peek(key K) (T, bool) {
+	var value T
+	var found bool
+	if c.protect(func() { value, found = c.primary.Peek(key) }, nil) {
+		return value, found
+	}
+	return c.fallback.Peek(key)
+}
+
+func (c *GracefulDegradationCache[K, T]) SetDefault(key K, value T) bool {
+	var ok bool
+	if c.protect(func() { ok = c.primary.SetDefault(key, value) }, nil) {
+		return ok
+	}
+	return c.fallback.SetDefault(key, value)
+}
+
+// Delete deletes the key from both caches, so the fallback does not return
+// its old value after a later panic.
+func (c *GracefulDegradationCache[K, T]) Delete(key K) {
+	c.protect(func() { c.primary.Delete(key) }, nil)
+	c.fallback.Delete(key)
+}
+
+// Disabled reports whether the ristretto cache has been disabled.
+func (c *GracefulDegradationCache[K, T]) Disabled() bool {
+	return c.disabled.Load()
+}
+
+// protect runs op on the ristretto cache and returns false if the cache is
+// disabled or op panicked. Panics for which ignore returns true are not
+// counted.
+func (c *GracefulDegradationCache[K, T]) protect(op func(), ignore func() bool) (ok bool) {
+	if c.disabled.Load() {
+		return false
+	}
+	defer func() {
+		r := recover()
+		if r == nil || ignore != nil && ignore() {
+			return
+		}
+		ok = false
+		if c.panics.Add(1) == c.maxPanics {
+			c.disabled.Store(true)
+			if c.alert != nil {
+				c.alert(fmt.Errorf("cache disabled after %d panics, last: %v", c.maxPanics, r))
+			}
+		}
+	}()
+	op()
+	return true
+}
@@ -0,0 +1,99 @@
+package main
+
+import "slices"
+
+// Patch is a change of a value cached by DeltaCache.
+type Patch[T any] interface {
+	Apply(base T) T
+	Cost() int64
+}
+
+// deltaValue is a base value with the patches applied to it since.
+type deltaValue[T any] struct {
+	base   T
+	deltas []Patch[T]
+}
+
+func (v *deltaValue[T]) assemble() T {
+	value := v.base
+	for _, delta := range v.deltas {
+		value = delta.Apply(value)
+	}
+	return value
+}
+
+// WithMaxDeltas sets the number of patches after which DeltaCache applies
+// them to the base value, 8 by default.
+func WithMaxDeltas[K comparable, T any](n int) Option[K, *deltaValue[T]] {
+	return func(o *options[K, *deltaValue[T]]) {
+		o.maxDeltas = n
+	}
+}
+
+// DeltaCache stores changes of cached values as patches of the value read
+// on the miss instead of whole new values. The cost of an entry is 1 for the
+// base value plus the costs of its patches. Patched values keep their
+// expiration.
+type DeltaCache[K comparable, T any] struct {
+	cache *ristrettoCache[K, *deltaValue[T]]
+}
+
+func NewCacheWithDeltaEncoding[K comparable, T any](opts ...Option[K, *deltaValue[T]]) (*DeltaCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, *deltaValue[T]]{WithMaxDeltas[K, T](8)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &DeltaCache[K, T]{cache: cache}, nil
+}
+
+// LoadOrStore returns the cached value with all patches applied.
+func (c *DeltaCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.cache.LoadOrStore(key, func() *deltaValue[T] {
+		return &deltaValue[T]{base: read()}
+	}).assemble()
+}
+
+func (c *DeltaCache[K, T]) Peek(key K) (T, bool) {
+	v, ok := c.cache.Peek(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return v.assemble(), true
+}
+
+// Patch adds the patch to the cached value, it returns ErrNotCached if the
+// key is not cached. Once the value has more than the maximum number of
+// patches, they are applied to the base value.
+func (c *DeltaCache[K, T]) Patch(key K, delta Patch[T]) error {
+	lock := c.cache.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	e, ok := c.cache.get(key)
+	if !ok {
+		return ErrNotCached
+	}
+	v := &deltaValue[T]{base: e.value.base, deltas: append(slices.Clip(e.value.deltas), delta)}
+	if len(v.deltas) > c.cache.maxDeltas {
+		v = &deltaValue[T]{base: v.assemble()}
+	}
+
+	cost := int64(1)
+	for _, delta := range v.deltas {
+		cost += delta.Cost()
+	}
+	patched := *e
+	patched.value = v
+	c.cache.storeWithCost(&patched, cost)
+	c.cache.cache.Wait()
+	return nil
+}
+
+func (c *DeltaCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *DeltaCache[K, T]) Close() {
+	c.cache.Close()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// WithDiskTTL sets how long DiskOverflowCache keeps entries on disk, 1h by
+// default.
+func WithDiskTTL[K comparable, T any](ttl time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.diskTTL = ttl
+	}
+}
+
+type diskRecord[K comparable, T any] struct {
+	Key       K
+	Value     T
+	ExpiresAt time.Time
+}
+
+// DiskOverflowCache writes entries rejected by ristretto's admission policy
+// to a file per entry in diskPath, so they can still be served from disk
+// after a miss instead of being read again. Entries are gob encoded, keys and
+// values gob cannot encode are dropped and counted in Stats.WriteErrors.
+// Entries expire from disk after the disk TTL and are removed once they are
+// looked up expired or deleted. Entries are written from ristretto's
+// goroutine.
+type DiskOverflowCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	diskPath    string
+	writeErrors atomic.Uint64
+}
+
+func NewCacheWithDiskOverflow[K comparable, T any](diskPath string, opts ...Option[K, T]) (*DiskOverflowCache[K, T], error) {
+	if err := os.MkdirAll(diskPath, 0o755); err != nil {
+		return nil, err
+	}
+	c := &DiskOverflowCache[K, T]{diskPath: diskPath}
+	cache, err := NewCache(append(append([]Option[K, T]{WithDiskTTL[K, T](time.Hour)}, opts...), func(o *options[K, T]) {
+		o.onReject = func(e *entry[K, T]) {
+			if err := c.spill(e); err != nil {
+				c.writeErrors.Add(1)
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *DiskOverflowCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *DiskOverflowCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		if value, ok := c.loadFromDisk(key); ok {
+			return value, nil
+		}
+		return read()
+	}, read)
+	return value, err
+}
+
+func (c *DiskOverflowCache[K, T]) Delete(key K) {
+	c.ristrettoCache.Delete(key)
+	os.Remove(c.path(key))
+}
+
+func (c *DiskOverflowCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.WriteErrors = c.writeErrors.Load()
+	return stats
+}
+
+// path returns the file of the key, named by the hash of its Go-syntax
+// representation.
+func (c *DiskOverflowCache[K, T]) path(key K) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%#v", key)))
+	return filepath.Join(c.diskPath, hex.EncodeToString(sum[:]))
+}
+
+func (c *DiskOverflowCache[K, T]) spill(e *entry[K, T]) error {
+	var buf bytes.Buffer
+	record := diskRecord[K, T]{Key: e.key, Value: e.value, ExpiresAt: time.Now().Add(c.diskTTL)}
+	if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+		return err
+	}
+
+	// write a temporary file which replaces the old one once it is complete
+	path := c.path(e.key)
+	f, err := os.CreateTemp(c.diskPath, filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (c *DiskOverflowCache[K, T]) loadFromDisk(key K) (T, bool) {
+	var record diskRecord[K, T]
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return record.Value, false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil || record.Key != key {
+		var zero T
+		return zero, false
+	}
+	if !time.Now().Before(record.ExpiresAt) {
+		os.Remove(path)
+		var zero T
+		return zero, false
+	}
+	return record.Value, true
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDiskOverflowServesRejectedEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCacheWithDiskOverflow(dir,
+		WithMaxCost[string, string](10),
+		// large values cost more than the whole cache, so they are rejected
+		WithSizer[string](SizerFunc[string](func(value string) int64 {
+			return int64(len(value))
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	large := strings.Repeat("x", 100)
+	for i := 0; i < 10; i++ {
+		c.SetDefault("large:"+strconv.Itoa(i), large+strconv.Itoa(i))
+	}
+	if _, ok := c.Peek("large:0"); ok {
+		t.Fatal("entry larger than the cache admitted")
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 10 {
+		t.Errorf("%d of 10 rejected entries written to disk", len(files))
+	}
+	if n := c.Stats().WriteErrors; n != 0 {
+		t.Errorf("%d write errors", n)
+	}
+
+	for i := 0; i < 10; i++ {
+		key := "large:" + strconv.Itoa(i)
+		value := c.LoadOrStore(key, func() string {
+			t.Errorf("%s read again", key)
+			return ""
+		})
+		if value != large+strconv.Itoa(i) {
+			t.Errorf("got %q for %s", value, key)
+		}
+	}
+}
+
+func TestDiskOverflowDeleteRemovesFile(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCacheWithDiskOverflow[string, string](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.spill(&entry[string, string]{key: "k", itemValue: itemValue[string]{value: "v"}}); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := c.loadFromDisk("k"); !ok || value != "v" {
+		t.Fatalf("loadFromDisk = %q, %v", value, ok)
+	}
+	c.Delete("k")
+	if _, ok := c.loadFromDisk("k"); ok {
+		t.Error("deleted entry still on disk")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"errors"
+)
+
+// EncryptedCache stores values gob encoded and encrypted with AES-GCM, so
+// ristretto never holds plain values. The nonce is stored in front of the
+// ciphertext.
+type EncryptedCache[K comparable, T any] struct {
+	cache *ristrettoCache[K, []byte]
+	aead  cipher.AEAD
+}
+
+func NewCacheWithEncryption[K comparable, T any](key [32]byte, opts ...Option[K, []byte]) (*EncryptedCache[K, T], error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedCache[K, T]{
+		cache: cache,
+		aead:  aead,
+	}, nil
+}
+
+func (c *EncryptedCache[K, T]) LoadOrStore(key K, read reader[T]) (T, error) {
+	return c.LoadOrStoreE(key, read.withError())
+}
+
+func (c *EncryptedCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	var readValue *T
+	// audits would compare ciphertexts sealed with different nonces
+	ciphertext, _, err := c.cache.loadOrStoreSource(key, func() ([]byte, error) {
+		value, err := read()
+		if err != nil {
+			return nil, err
+		}
+		readValue = &value
+		return c.seal(value)
+	}, nil)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if readValue != nil {
+		return *readValue, nil
+	}
+	return c.open(ciphertext)
+}
+
+func (c *EncryptedCache[K, T]) Peek(key K) (T, bool, error) {
+	ciphertext, ok := c.cache.Peek(key)
+	if !ok {
+		var zero T
+		return zero, false, nil
+	}
+	value, err := c.open(ciphertext)
+	return value, err == nil, err
+}
+
+func (c *EncryptedCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *EncryptedCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+func (c *EncryptedCache[K, T]) seal(value T) ([]byte, error) {
+	var plaintext bytes.Buffer
+	if err := gob.NewEncoder(&plaintext).Encode(&value); err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+plaintext.Len()+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext.Bytes(), nil), nil
+}
+
+func (c *EncryptedCache[K, T]) open(ciphertext []byte) (T, error) {
+	var value T
+	if len(ciphertext) < c.aead.NonceSize() {
+		return value, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return value, err
+	}
+	err = gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&value)
+	return value, err
+}
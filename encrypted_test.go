@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptedValuesAreNotReadable(t *testing.T) {
+	var key [32]byte
+	copy(key[:], "0123456789abcdef0123456789abcdef")
+	c, err := NewCacheWithEncryption[string, string](key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const secret = "alice@example.com"
+	if _, err := c.LoadOrStore("email", func() string { return secret }); err != nil {
+		t.Fatal(err)
+	}
+	raw, ok := c.cache.Peek("email")
+	if !ok {
+		t.Fatal("value not cached")
+	}
+	if bytes.Contains(raw, []byte(secret)) {
+		t.Errorf("ristretto holds the plain value: %q", raw)
+	}
+	if value, ok, err := c.Peek("email"); err != nil || !ok || value != secret {
+		t.Errorf("Peek = %q, %v, %v, want %q", value, ok, err, secret)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "errors"
+
+var ErrEntryTooLarge = errors.New("entry exceeds the maximum entry cost")
+
+// WithMaxEntryCost rejects entries costing more than maxCost, so a single
+// large entry cannot evict all other entries. LoadOrStoreE returns the value
+// along with ErrEntryTooLarge without caching it, SetDefault reports false.
+func WithMaxEntryCost[K comparable, T any](maxCost int64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.maxEntryCost = maxCost
+	}
+}
+
+func NewCacheWithCostCapPerEntry[K comparable, T any](maxEntryCost int64, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithMaxEntryCost[K, T](maxEntryCost))...)
+}
+
+func (c *ristrettoCache[K, T]) tooLarge(e *entry[K, T]) bool {
+	return c.maxEntryCost > 0 && e.cost > c.maxEntryCost
+}
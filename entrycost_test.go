@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaxEntryCostRejectsLargeValues(t *testing.T) {
+	c, err := NewCacheWithCostCapPerEntry(1<<20,
+		WithSizer[string](SizerFunc[[]byte](func(value []byte) int64 { return int64(len(value)) })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	small, large := make([]byte, 512<<10), make([]byte, 2<<20)
+	if _, err := c.LoadOrStoreE("small", func() ([]byte, error) { return small, nil }); err != nil {
+		t.Errorf("512KB entry: %v", err)
+	}
+	if _, ok := c.Peek("small"); !ok {
+		t.Error("512KB entry not cached")
+	}
+
+	value, err := c.LoadOrStoreE("large", func() ([]byte, error) { return large, nil })
+	if !errors.Is(err, ErrEntryTooLarge) || len(value) != len(large) {
+		t.Errorf("2MB entry: got %d bytes, %v, want the value with %v", len(value), err, ErrEntryTooLarge)
+	}
+	if c.SetDefault("large", large) {
+		t.Error("SetDefault accepted the 2MB entry")
+	}
+	if _, ok := c.Peek("large"); ok {
+		t.Error("2MB entry cached")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// EpochCache expires entries in batches instead of by their TTL. Time is
+// divided into epochs of epochDuration, entries stored in epoch E are
+// deleted when epoch E+2 starts.
+type EpochCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	epochDuration time.Duration
+	start         time.Time
+	stop          chan struct{}
+
+	mu sync.Mutex
+	// keys holds the keys stored in each epoch, keyEpoch the epoch each key
+	// was stored in last
+	keys     map[int64]map[K]struct{}
+	keyEpoch map[K]int64
+}
+
+func NewCacheWithEpochBasedGC[K comparable, T any](epochDuration time.Duration, opts ...Option[K, T]) (*EpochCache[K, T], error) {
+	c := &EpochCache[K, T]{
+		epochDuration: epochDuration,
+		start:         time.Now(),
+		stop:          make(chan struct{}),
+		keys:          make(map[int64]map[K]struct{}),
+		keyEpoch:      make(map[K]int64),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = c.track
+		// entries are already deleted, this covers the time until then
+		o.isFresh = func(e *entry[K, T]) bool {
+			return c.epoch(e.computedAt) >= c.epoch(time.Now())-1
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	// entries never expire by TTL
+	cache.defaultTTL = 0
+	c.ristrettoCache = cache
+
+	go c.run()
+	return c, nil
+}
+
+func (c *EpochCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *EpochCache[K, T]) run() {
+	next := c.epoch(time.Now()) + 1
+	timer := time.NewTimer(time.Until(c.start.Add(time.Duration(next) * c.epochDuration)))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-c.stop:
+			return
+		}
+		c.collect(next - 2)
+		next++
+		timer.Reset(time.Until(c.start.Add(time.Duration(next) * c.epochDuration)))
+	}
+}
+
+// collect deletes the keys stored in the epoch and all epochs before it.
+func (c *EpochCache[K, T]) collect(epoch int64) {
+	// the lock keeps keys stored again meanwhile from being deleted
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e, keys := range c.keys {
+		if e > epoch {
+			continue
+		}
+		for key := range keys {
+			c.cache.Del(key)
+			delete(c.keyEpoch, key)
+		}
+		delete(c.keys, e)
+	}
+}
+
+func (c *EpochCache[K, T]) track(e *entry[K, T]) {
+	epoch := c.epoch(e.computedAt)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.keyEpoch[e.key]; ok {
+		if old == epoch {
+			return
+		}
+		delete(c.keys[old], e.key)
+	}
+	if c.keys[epoch] == nil {
+		c.keys[epoch] = make(map[K]struct{})
+	}
+	c.keys[epoch][e.key] = struct{}{}
+	c.keyEpoch[e.key] = epoch
+}
+
+func (c *EpochCache[K, T]) epoch(t time.Time) int64 {
+	return int64(t.Sub(c.start) / c.epochDuration)
+}
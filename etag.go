@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+)
+
+type etagged[T any] struct {
+	value T
+	etag  string
+}
+
+// ETagCache stores an entity tag with every value, the hex encoded SHA-256
+// hash of its gob encoding, so callers can answer conditional requests
+// without comparing values. Values gob cannot encode get an empty ETag which
+// never matches.
+type ETagCache[K ~string, T any] struct {
+	cache *ristrettoCache[K, etagged[T]]
+	etag  func(value T) string
+}
+
+func NewCacheWithETagSupport[K ~string, T any](opts ...Option[K, etagged[T]]) (*ETagCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ETagCache[K, T]{cache: cache, etag: sha256ETag[T]}, nil
+}
+
+// LoadOrStoreWithETag returns the cached value and its ETag, notModified is
+// true if the ETag equals etag.
+func (c *ETagCache[K, T]) LoadOrStoreWithETag(key K, etag string, read reader[T]) (T, string, bool) {
+	e := c.cache.LoadOrStore(key, func() etagged[T] {
+		value := read()
+		return etagged[T]{value: value, etag: c.etag(value)}
+	})
+	return e.value, e.etag, e.etag != "" && e.etag == etag
+}
+
+func (c *ETagCache[K, T]) Peek(key K) (T, string, bool) {
+	e, ok := c.cache.Peek(key)
+	return e.value, e.etag, ok
+}
+
+func (c *ETagCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *ETagCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+func sha256ETag[T any](value T) string {
+	data, ok := gobEncode(value)
+	if !ok {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func gobEncode[T any](value T) ([]byte, bool) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// WithEvictionWorkers sets the number of goroutines draining the eviction
+// queue of CacheWithEvictionQueue.
+func WithEvictionWorkers[K comparable, T any](n int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.evictionWorkers = n
+	}
+}
+
+// WithRateLimitedEviction limits the eviction callbacks of
+// CacheWithEvictionQueue to rps calls per second, evictions beyond the rate
+// wait in the queue.
+func WithRateLimitedEviction[K comparable, T any](rps float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.evictionRate = rps
+	}
+}
+
+// NewCacheWithRateLimitedEviction creates a CacheWithEvictionQueue calling
+// the eviction callback at most rps times per second. Up to 1024 evictions
+// are queued.
+func NewCacheWithRateLimitedEviction[K comparable, T any](rps float64, opts ...Option[K, T]) (*CacheWithEvictionQueue[K, T], error) {
+	return NewCacheWithEvictionQueue(1024, append(opts, WithRateLimitedEviction[K, T](rps))...)
+}
+
+type evictionEvent[K comparable, T any] struct {
+	key   K
+	value T
+}
+
+// CacheWithEvictionQueue runs the eviction callback off ristretto's goroutine
+// so slow callbacks do not block the cache. At most maxPending evictions are
+// queued, further evictions are dropped.
+type CacheWithEvictionQueue[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	events  chan evictionEvent[K, T]
+	dropped atomic.Uint64
+	wg      sync.WaitGroup
+}
+
+func NewCacheWithEvictionQueue[K comparable, T any](maxPending int, opts ...Option[K, T]) (*CacheWithEvictionQueue[K, T], error) {
+	o := options[K, T]{evictionWorkers: 1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	q := &CacheWithEvictionQueue[K, T]{
+		events: make(chan evictionEvent[K, T], maxPending),
+	}
+	cache, err := NewCache(append(opts, WithOnEvict(q.enqueue))...)
+	if err != nil {
+		return nil, err
+	}
+	q.ristrettoCache = cache
+
+	onEvict := o.onEvict
+	if onEvict == nil {
+		onEvict = func(K, T) {}
+	}
+	// the limiter is shared by all workers
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if o.evictionRate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(o.evictionRate), 1)
+	}
+	for i := 0; i < o.evictionWorkers; i++ {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for event := range q.events {
+				limiter.Wait(context.Background())
+				onEvict(event.key, event.value)
+			}
+		}()
+	}
+
+	return q, nil
+}
+
+func (q *CacheWithEvictionQueue[K, T]) enqueue(key K, value T) {
+	select {
+	case q.events <- evictionEvent[K, T]{key: key, value: value}:
+	default:
+		q.dropped.Add(1)
+	}
+}
+
+func (q *CacheWithEvictionQueue[K, T]) Stats() Stats {
+	stats := q.ristrettoCache.Stats()
+	stats.DroppedEvictions = q.dropped.Load()
+	return stats
+}
+
+// Close closes the cache and waits for the queued evictions to be processed.
+func (q *CacheWithEvictionQueue[K, T]) Close() {
+	q.ristrettoCache.Close()
+	close(q.events)
+	q.wg.Wait()
+}
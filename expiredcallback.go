@@ -0,0 +1,23 @@
+package main
+
+import "time"
+
+// WithKeyExpiredCallback registers a callback for entries ristretto removed
+// because their TTL passed, unlike WithOnEvict it is not called for entries
+// evicted because of their cost. ristretto removes expired entries every few
+// seconds, so the callback can be called a few seconds after the expiry.
+func WithKeyExpiredCallback[K comparable, T any](fn func(key K, value T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.onExpired = fn
+	}
+}
+
+func NewCacheWithKeyExpiredCallback[K comparable, T any](fn func(K, T), opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithKeyExpiredCallback(fn))...)
+}
+
+// expired reports whether the entry was removed for its TTL, including the
+// stale TTL it is kept for.
+func (e *entry[K, T]) expired(staleTTL time.Duration) bool {
+	return e.ttl > 0 && !time.Now().Before(e.expiresAt.Add(staleTTL))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyExpiredCallbackFiresAfterTTL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for ristretto's expiry cleanup")
+	}
+	expired := make(chan time.Time, 1)
+	c, err := NewCacheWithKeyExpiredCallback(func(key string, value int) {
+		if key == "k" && value == 1 {
+			expired <- time.Now()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.defaultTTL = time.Second
+
+	stored := time.Now()
+	c.SetDefault("k", 1)
+	select {
+	case at := <-expired:
+		// ristretto cleans up expired entries in buckets of 5 seconds
+		if after := at.Sub(stored); after < time.Second || after > 12*time.Second {
+			t.Errorf("callback fired %s after storing with a TTL of 1s", after)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("callback not fired")
+	}
+}
+
+func TestKeyExpiredCallbackIgnoresDeletes(t *testing.T) {
+	fired := make(chan string, 1)
+	c, err := NewCacheWithKeyExpiredCallback(func(key string, _ int) { fired <- key })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("k", 1)
+	c.Delete("k")
+	select {
+	case key := <-fired:
+		t.Errorf("callback fired for the deleted key %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "time"
+
+// ExplicitLockCache exposes the per-key locks of the cache, so callers can
+// hold them across several operations, e.g. reading from a database,
+// computing a value, storing it and updating the database. LoadOrStore takes
+// the same locks, it must not be called for a key locked by the caller.
+//
+// Every Lock must be followed by Unlock, also when the operations fail:
+//
+//	c.Lock(key)
+//	defer c.Unlock(key)
+type ExplicitLockCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithExplicitLocking[K comparable, T any](opts ...Option[K, T]) (*ExplicitLockCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ExplicitLockCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *ExplicitLockCache[K, T]) Lock(key K) {
+	c.keyLock(key).Lock()
+}
+
+func (c *ExplicitLockCache[K, T]) Unlock(key K) {
+	c.keyLock(key).Unlock()
+}
+
+// Get returns the cached value, it does not take the key lock.
+func (c *ExplicitLockCache[K, T]) Get(key K) (T, bool) {
+	return c.Peek(key)
+}
+
+// Set stores the value with the given TTL and reports whether it was
+// accepted by ristretto, it does not take the key lock.
+func (c *ExplicitLockCache[K, T]) Set(key K, value T, ttl time.Duration) bool {
+	ok := c.setWithTTL(key, value, ttl)
+	c.cache.Wait()
+	return ok
+}
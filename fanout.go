@@ -0,0 +1,54 @@
+package main
+
+import "sync/atomic"
+
+// WithFanout sets the caches FanoutCache copies newly read values to.
+func WithFanout[K comparable, T any](targets ...Cache[K, T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.fanout = targets
+	}
+}
+
+// FanoutCache stores newly read values in the target caches as well. The
+// targets are updated concurrently and a target not accepting the value is
+// only counted.
+type FanoutCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	errors atomic.Uint64
+}
+
+func NewFanoutCache[K comparable, T any](opts ...Option[K, T]) (*FanoutCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &FanoutCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *FanoutCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *FanoutCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, err := read()
+		if err == nil {
+			for _, target := range c.fanout {
+				go func(target Cache[K, T]) {
+					if !target.SetDefault(key, value) {
+						c.errors.Add(1)
+					}
+				}(target)
+			}
+		}
+		return value, err
+	}, read)
+	return value, err
+}
+
+func (c *FanoutCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.FanoutErrors = c.errors.Load()
+	return stats
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	sketchDepth = 4
+	sketchWidth = 1 << 16
+	// topCandidates is the number of keys tracked for TopFrequent
+	topCandidates = 1024
+)
+
+// FrequencySketchCache counts accesses of keys in its own count-min sketch,
+// unlike ristretto's internal TinyLFU counters the estimates are exposed,
+// e.g. for pre-warming a new cache with the most frequent keys.
+type FrequencySketchCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	sketch *countMinSketch
+	// top holds the *atomic.Uint32 counts of the candidates for
+	// TopFrequent by key. Once it is full, floor is at most the smallest
+	// count of a candidate.
+	top   sync.Map
+	floor atomic.Uint32
+
+	// mu is held while adding or replacing candidates
+	mu         sync.Mutex
+	candidates int
+}
+
+func NewCacheWithFrequencySketch[K comparable, T any](opts ...Option[K, T]) (*FrequencySketchCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &FrequencySketchCache[K, T]{
+		ristrettoCache: cache,
+		sketch:         newCountMinSketch(sketchDepth, sketchWidth),
+	}, nil
+}
+
+func (c *FrequencySketchCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	c.record(key)
+	return c.ristrettoCache.LoadOrStore(key, read)
+}
+
+func (c *FrequencySketchCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	c.record(key)
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *FrequencySketchCache[K, T]) Peek(key K) (T, bool) {
+	c.record(key)
+	return c.ristrettoCache.Peek(key)
+}
+
+// Frequency returns the estimated number of accesses of the key, the
+// estimate may be too high but never too low.
+func (c *FrequencySketchCache[K, T]) Frequency(key K) uint32 {
+	return c.sketch.estimate(key)
+}
+
+// TopFrequent returns up to n of the most frequently accessed keys, most
+// frequent first. Only the 1024 most frequent keys are tracked.
+func (c *FrequencySketchCache[K, T]) TopFrequent(n int) []K {
+	var keys []K
+	counts := make(map[K]uint32)
+	c.top.Range(func(key, candidate any) bool {
+		keys = append(keys, key.(K))
+		counts[key.(K)] = candidate.(*atomic.Uint32).Load()
+		return true
+	})
+
+	sort.Slice(keys, func(i, j int) bool {
+		return counts[keys[i]] > counts[keys[j]]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// record counts an access of the key. Only keys more frequent than the
+// floor take the lock to become candidates, the accesses of candidates are
+// recorded without locking.
+func (c *FrequencySketchCache[K, T]) record(key K) {
+	count := c.sketch.increment(key)
+	if candidate, ok := c.top.Load(key); ok {
+		storeMax(candidate.(*atomic.Uint32), count)
+		return
+	}
+	if count <= c.floor.Load() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.top.Load(key); ok {
+		return
+	}
+	if c.candidates < topCandidates {
+		c.addCandidate(key, count)
+		c.candidates++
+		return
+	}
+	// replace the least frequent candidate if the key is more frequent
+	minKey, minCount := c.leastFrequent()
+	if minCount < count {
+		c.top.Delete(minKey)
+		c.addCandidate(key, count)
+		_, minCount = c.leastFrequent()
+	}
+	c.floor.Store(minCount)
+}
+
+func (c *FrequencySketchCache[K, T]) addCandidate(key K, count uint32) {
+	candidate := &atomic.Uint32{}
+	candidate.Store(count)
+	c.top.Store(key, candidate)
+}
+
+// leastFrequent returns the candidate with the smallest count.
+func (c *FrequencySketchCache[K, T]) leastFrequent() (K, uint32) {
+	var minKey K
+	minCount := ^uint32(0)
+	c.top.Range(func(key, candidate any) bool {
+		if count := candidate.(*atomic.Uint32).Load(); count < minCount {
+			minKey, minCount = key.(K), count
+		}
+		return true
+	})
+	return minKey, minCount
+}
+
+// storeMax stores count unless the counter holds a larger count.
+func storeMax(counter *atomic.Uint32, count uint32) {
+	for {
+		old := counter.Load()
+		if old >= count || counter.CompareAndSwap(old, count) {
+			return
+		}
+	}
+}
+
+// countMinSketch estimates frequencies with depth rows of width counters,
+// the estimate is the smallest counter of the key in all rows. Counters are
+// updated atomically, so the sketch needs no lock.
+type countMinSketch struct {
+	counters [][]uint32
+	width    uint64
+}
+
+func newCountMinSketch(depth, width int) *countMinSketch {
+	counters := make([][]uint32, depth)
+	for i := range counters {
+		counters[i] = make([]uint32, width)
+	}
+	return &countMinSketch{counters: counters, width: uint64(width)}
+}
+
+// increment counts an access of the key and returns its new estimate.
+func (s *countMinSketch) increment(key interface{}) uint32 {
+	h1, h2 := doubleHash(key)
+
+	estimate := ^uint32(0)
+	for i, row := range s.counters {
+		counter := &row[(h1+uint64(i)*h2)%s.width]
+		for {
+			count := atomic.LoadUint32(counter)
+			if count == ^uint32(0) || atomic.CompareAndSwapUint32(counter, count, count+1) {
+				estimate = min(estimate, count+1)
+				break
+			}
+		}
+	}
+	return estimate
+}
+
+// reset sets all counters to zero, accesses counted meanwhile may be kept.
+func (s *countMinSketch) reset() {
+	for _, row := range s.counters {
+		for i := range row {
+			atomic.StoreUint32(&row[i], 0)
+		}
+	}
+}
+
+func (s *countMinSketch) estimate(key interface{}) uint32 {
+	h1, h2 := doubleHash(key)
+
+	estimate := ^uint32(0)
+	for i, row := range s.counters {
+		estimate = min(estimate, atomic.LoadUint32(&row[(h1+uint64(i)*h2)%s.width]))
+	}
+	return estimate
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestFrequencySketchTopFrequent(t *testing.T) {
+	c, err := NewCacheWithFrequencySketch[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for _, n := range []int{100, 50} {
+		key := "hot" + strconv.Itoa(n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Peek(key)
+			}()
+		}
+	}
+	// more cold keys than candidates are tracked
+	for i := 0; i < 2*topCandidates; i++ {
+		c.Peek(strconv.Itoa(i))
+	}
+	wg.Wait()
+
+	if top := c.TopFrequent(2); !slices.Equal(top, []string{"hot100", "hot50"}) {
+		t.Errorf("TopFrequent(2) = %v", top)
+	}
+	if n := c.Frequency("hot100"); n < 100 {
+		t.Errorf("Frequency() = %d, want at least 100", n)
+	}
+}
+
+func BenchmarkFrequencySketchHits(b *testing.B) {
+	c, err := NewCacheWithFrequencySketch[int, int]()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.Close()
+	for key := 0; key < 1000; key++ {
+		c.SetDefault(key, key)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		key := 0
+		for pb.Next() {
+			c.LoadOrStore(key%1000, func() int { return key })
+			key++
+		}
+	})
+}
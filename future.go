@@ -0,0 +1,73 @@
+package main
+
+import "sync"
+
+// Future is a value reserved in a FutureCache which is computed elsewhere.
+type Future[T any] struct {
+	done     chan struct{}
+	once     sync.Once
+	value    T
+	err      error
+	complete func(value T, err error)
+}
+
+// Complete sets the result of the future and wakes up its waiters, only the
+// first call has an effect.
+func (f *Future[T]) Complete(value T, err error) {
+	f.once.Do(func() {
+		f.value, f.err = value, err
+		f.complete(value, err)
+		close(f.done)
+	})
+}
+
+// Wait blocks until the future is completed and returns its result.
+func (f *Future[T]) Wait() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// FutureCache lets callers reserve keys whose values they compute
+// themselves, LoadOrStore waits for the future of a reserved key instead of
+// calling its reader. Values of futures completed without an error are
+// cached.
+type FutureCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	futures sync.Map
+}
+
+func NewCacheWithFutureValue[K comparable, T any](opts ...Option[K, T]) (*FutureCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &FutureCache[K, T]{ristrettoCache: cache}, nil
+}
+
+// Reserve returns the future of the key, reserving it if it is not reserved
+// yet. The future has to be completed, or LoadOrStore blocks forever for the
+// key.
+func (c *FutureCache[K, T]) Reserve(key K) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	f.complete = func(value T, err error) {
+		if err == nil {
+			c.SetDefault(key, value)
+		}
+		c.futures.CompareAndDelete(key, f)
+	}
+	actual, _ := c.futures.LoadOrStore(key, f)
+	return actual.(*Future[T])
+}
+
+func (c *FutureCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE returns the result of the future if the key is reserved.
+func (c *FutureCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if f, ok := c.futures.Load(key); ok {
+		return f.(*Future[T]).Wait()
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// WithGCLeakDetection turns the leak detection of GCHintsCache on or off.
+func WithGCLeakDetection[K comparable, T any](enabled bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.gcLeakDetection = enabled
+	}
+}
+
+// GCHintsCache logs a warning for entries garbage collected while the cache
+// still considers them stored, i.e. ristretto dropped them without reporting
+// it. Entries of a closed cache are not reported.
+type GCHintsCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	leaks *leakIndex
+}
+
+// leakIndex holds the addresses of the stored entries by key. The finalizers
+// of the entries only reference the index, which does not keep the entries
+// or the cache alive.
+type leakIndex struct {
+	closed  atomic.Bool
+	entries sync.Map
+}
+
+func (l *leakIndex) finalized(key any, addr uintptr) {
+	if l.closed.Load() {
+		return
+	}
+	if stored, ok := l.entries.Load(key); ok && stored == addr {
+		slog.Warn("cache entry garbage collected while stored", "key", key)
+	}
+}
+
+func NewCacheWithGarbageCollectionHints[K comparable, T any](opts ...Option[K, T]) (*GCHintsCache[K, T], error) {
+	leaks := &leakIndex{}
+	finalize := func(e *entry[K, T]) {
+		leaks.finalized(e.key, uintptr(unsafe.Pointer(e)))
+	}
+	cache, err := NewCache(append([]Option[K, T]{WithGCLeakDetection[K, T](true)}, append(opts, func(o *options[K, T]) {
+		if !o.gcLeakDetection {
+			return
+		}
+		o.onSet = func(e *entry[K, T]) {
+			leaks.entries.Store(e.key, uintptr(unsafe.Pointer(e)))
+			runtime.SetFinalizer(e, finalize)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			leaks.entries.CompareAndDelete(e.key, uintptr(unsafe.Pointer(e)))
+		}
+	})...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &GCHintsCache[K, T]{ristrettoCache: cache, leaks: leaks}, nil
+}
+
+func (c *GCHintsCache[K, T]) Close() {
+	c.leaks.closed.Store(true)
+	c.ristrettoCache.Close()
+}
@@ -0,0 +1,27 @@
+package main
+
+// GenerationCache stores the generation it was created with alongside each
+// value and treats entries of other generations as misses. Bumping the
+// generation on deployments which change the data model invalidates all
+// values cached before, they are evicted by their TTL.
+type GenerationCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithGenerationControl[K comparable, T any](generation int, opts ...Option[K, T]) (*GenerationCache[K, T], error) {
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.generation = generation
+		o.isFresh = func(e *entry[K, T]) bool {
+			return e.generation == generation
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	return &GenerationCache[K, T]{ristrettoCache: cache}, nil
+}
+
+// Generation returns the generation of values stored by the cache.
+func (c *GenerationCache[K, T]) Generation() int {
+	return c.generation
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RemoteCache is the cache of another region.
+type RemoteCache[K comparable, T any] interface {
+	Get(key K) (T, bool, error)
+	Delete(key K) error
+}
+
+// WithRemoteRegions adds caches of further regions GeoAffinityCache queries
+// on misses.
+func WithRemoteRegions[K comparable, T any](regions []RemoteCache[K, T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.remoteRegions = append(o.remoteRegions, regions...)
+	}
+}
+
+// WithMaxRemoteParallelism limits the number of remote caches GeoAffinityCache
+// queries at the same time, all of them are queried at once by default.
+func WithMaxRemoteParallelism[K comparable, T any](n int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.remoteParallel = n
+	}
+}
+
+// GeoAffinityCache looks up local misses in the caches of other regions
+// before reading them, the first value found remotely is cached locally.
+// Deletes are applied to all regions.
+type GeoAffinityCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	region string
+	errors atomic.Uint64
+}
+
+func NewCacheWithGeographicAffinity[K comparable, T any](region string, remoteCache RemoteCache[K, T], opts ...Option[K, T]) (*GeoAffinityCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{WithRemoteRegions([]RemoteCache[K, T]{remoteCache})}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &GeoAffinityCache[K, T]{
+		ristrettoCache: cache,
+		region:         region,
+	}, nil
+}
+
+// Region returns the region of the local cache.
+func (c *GeoAffinityCache[K, T]) Region() string {
+	return c.region
+}
+
+func (c *GeoAffinityCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *GeoAffinityCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	return c.ristrettoCache.LoadOrStoreE(key, func() (T, error) {
+		if value, ok := c.getRemote(key); ok {
+			return value, nil
+		}
+		return read()
+	})
+}
+
+// Delete deletes the key locally and waits for the remote deletes.
+func (c *GeoAffinityCache[K, T]) Delete(key K) {
+	c.ristrettoCache.Delete(key)
+
+	var wg sync.WaitGroup
+	for _, remote := range c.remoteRegions {
+		wg.Add(1)
+		go func(remote RemoteCache[K, T]) {
+			defer wg.Done()
+			if err := remote.Delete(key); err != nil {
+				c.errors.Add(1)
+			}
+		}(remote)
+	}
+	wg.Wait()
+}
+
+func (c *GeoAffinityCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.RemoteErrors = c.errors.Load()
+	return stats
+}
+
+// getRemote queries the remote caches and returns the first value found.
+func (c *GeoAffinityCache[K, T]) getRemote(key K) (T, bool) {
+	parallel := c.remoteParallel
+	if parallel <= 0 {
+		parallel = len(c.remoteRegions)
+	}
+	sem := make(chan struct{}, parallel)
+	found := make(chan T, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for _, remote := range c.remoteRegions {
+		wg.Add(1)
+		go func(remote RemoteCache[K, T]) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+			defer func() { <-sem }()
+
+			value, ok, err := remote.Get(key)
+			if err != nil {
+				c.errors.Add(1)
+				return
+			}
+			if ok {
+				select {
+				case found <- value:
+				default:
+				}
+			}
+		}(remote)
+	}
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	value, ok := <-found
+	return value, ok
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// globalEvictionCallbacks holds the callbacks registered with
+// RegisterGlobalEvictionCallback.
+var globalEvictionCallbacks evictionBus
+
+type evictionBus struct {
+	// callbacks holds a func(key, value any) per registration
+	callbacks sync.Map
+	count     atomic.Int64
+}
+
+func (b *evictionBus) broadcast(key, value any) {
+	b.callbacks.Range(func(_, callback any) bool {
+		callback.(func(key, value any))(key, value)
+		return true
+	})
+}
+
+// RegisterGlobalEvictionCallback calls fn for entries evicted because of
+// their cost or TTL from any cache in the process whose values are of type
+// T. Callbacks are called from ristretto's goroutines. The returned function
+// unregisters the callback.
+func RegisterGlobalEvictionCallback[T any](fn func(key any, value T)) func() {
+	registration := new(int)
+	globalEvictionCallbacks.callbacks.Store(registration, func(key, value any) {
+		if value, ok := value.(T); ok {
+			fn(key, value)
+		}
+	})
+	globalEvictionCallbacks.count.Add(1)
+
+	return func() {
+		if _, ok := globalEvictionCallbacks.callbacks.LoadAndDelete(registration); ok {
+			globalEvictionCallbacks.count.Add(-1)
+		}
+	}
+}
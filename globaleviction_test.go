@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// evictedValue is only cached by this test, so other caches of the process
+// do not call the callbacks.
+type evictedValue int
+
+func TestGlobalEvictionCallbacks(t *testing.T) {
+	var evicted atomic.Int64
+	unregister := RegisterGlobalEvictionCallback(func(key any, value evictedValue) {
+		evicted.Add(1)
+	})
+	var caches []*ristrettoCache[int, evictedValue]
+	for i := 0; i < 2; i++ {
+		c, err := NewCache(WithMaxCost[int, evictedValue](10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		caches = append(caches, c)
+	}
+	fill := func(c *ristrettoCache[int, evictedValue]) {
+		for i := 0; i < 1000; i++ {
+			c.SetDefault(i, evictedValue(i))
+		}
+	}
+
+	for _, c := range caches {
+		before := evicted.Load()
+		fill(c)
+		if evicted.Load() == before {
+			t.Error("no evictions of a cache reported")
+		}
+	}
+
+	unregister()
+	unregister()
+	// let callbacks of evictions in progress return
+	time.Sleep(10 * time.Millisecond)
+	before := evicted.Load()
+	fill(caches[0])
+	if n := evicted.Load() - before; n != 0 {
+		t.Errorf("%d evictions reported after unregistering", n)
+	}
+	if n := globalEvictionCallbacks.count.Load(); n != 0 {
+		t.Errorf("%d callbacks registered after unregistering", n)
+	}
+}
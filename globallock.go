@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// GlobalLockCache is a map guarded by a single lock. Readers run while the
+// lock is held, so it is only suitable for very small caches and as a
+// baseline for the other implementations.
+type GlobalLockCache[K comparable, T any] struct {
+	lock   sync.RWMutex
+	values map[K]*itemValue[T]
+}
+
+func NewCacheWithGlobalLock[K comparable, T any]() *GlobalLockCache[K, T] {
+	return &GlobalLockCache[K, T]{
+		values: make(map[K]*itemValue[T]),
+	}
+}
+
+func (c *GlobalLockCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.values[key]; ok && time.Now().Before(value.expiresAt) {
+		return value.value
+	}
+
+	now := time.Now()
+	value := &itemValue[T]{value: read(), computedAt: now, expiresAt: now.Add(ttl)}
+	c.values[key] = value
+	return value.value
+}
+
+// Peek returns the cached value without reading it on a miss.
+func (c *GlobalLockCache[K, T]) Peek(key K) (T, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, ok := c.values[key]
+	if !ok || !time.Now().Before(value.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return value.value, true
+}
+
+// SetDefault stores the value with the default TTL.
+func (c *GlobalLockCache[K, T]) SetDefault(key K, value T) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	c.values[key] = &itemValue[T]{value: value, computedAt: now, expiresAt: now.Add(ttl)}
+	return true
+}
+
+func (c *GlobalLockCache[K, T]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.values, key)
+}
@@ -3,14 +3,23 @@ module github.com/jakubtomany/cachetest
 go 1.21.0
 
 require (
+	github.com/RoaringBitmap/roaring v1.6.0
 	github.com/dgraph-io/ristretto v0.1.1
+	github.com/google/btree v1.1.2
 	github.com/google/uuid v1.4.0
+	github.com/klauspost/compress v1.17.4
+	github.com/pierrec/lz4/v4 v4.1.19
+	golang.org/x/sync v0.5.0
+	golang.org/x/time v0.5.0
+	google.golang.org/protobuf v1.31.0
 )
 
 require (
+	github.com/bits-and-blooms/bitset v1.2.0 // indirect
 	github.com/cespare/xxhash/v2 v2.1.1 // indirect
 	github.com/dustin/go-humanize v1.0.0 // indirect
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b // indirect
+	github.com/mschoch/smat v0.2.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	golang.org/x/sys v0.0.0-20221010170243-090e33056c14 // indirect
 )
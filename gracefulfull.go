@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// gracefulVictimSamples is the number of cached entries GracefulFullCache
+// compares a new entry with.
+const gracefulVictimSamples = 5
+
+// GracefulFullCache does not store newly read values once the cache is full
+// unless their key is estimated to be accessed more often than a sample of
+// the cached keys, the value is returned to the caller either way. This
+// keeps random keys from evicting frequently used entries. Accesses are
+// counted in a count-min sketch.
+type GracefulFullCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	sketch *countMinSketch
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+	// used is the total cost of the stored entries
+	used atomic.Int64
+}
+
+func NewCacheWithGracefulFullCache[K comparable, T any](opts ...Option[K, T]) (*GracefulFullCache[K, T], error) {
+	c := &GracefulFullCache[K, T]{sketch: newCountMinSketch(sketchDepth, sketchWidth)}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+			c.used.Add(e.cost)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+			c.used.Add(-e.cost)
+		}
+		o.storeMiss = c.storeMiss
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *GracefulFullCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *GracefulFullCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	c.sketch.increment(key)
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+// storeMiss does not store the entry if the cache is full and the key is
+// accessed less often than the cached ones.
+func (c *GracefulFullCache[K, T]) storeMiss(e *entry[K, T]) error {
+	if c.full(e.cost) && !c.admit(e.key) {
+		return nil
+	}
+	c.setEntry(e)
+	c.cache.Wait()
+	return nil
+}
+
+// full reports whether storing an entry of the cost makes ristretto evict
+// entries.
+func (c *GracefulFullCache[K, T]) full(cost int64) bool {
+	return c.used.Load()+cost > c.cache.MaxCost()
+}
+
+// admit reports whether the key is estimated to be accessed more often than
+// the least frequent of a sample of the cached keys.
+func (c *GracefulFullCache[K, T]) admit(key K) bool {
+	incoming := c.sketch.estimate(key)
+	victim := ^uint32(0)
+	sampled := 0
+	c.entries.Range(func(cached, _ any) bool {
+		victim = min(victim, c.sketch.estimate(cached))
+		sampled++
+		return sampled < gracefulVictimSamples
+	})
+	return sampled == 0 || incoming > victim
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestGracefulFullKeepsFrequentKeys(t *testing.T) {
+	c, err := NewCacheWithGracefulFullCache(WithMaxCost[string, int](10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 10; i++ {
+		key := "hot:" + strconv.Itoa(i)
+		for j := 0; j < 5; j++ {
+			c.LoadOrStore(key, func() int { return i })
+		}
+	}
+	// keys accessed once are not stored while the cache is full
+	for i := 0; i < 100; i++ {
+		key := "cold:" + strconv.Itoa(i)
+		if value := c.LoadOrStore(key, func() int { return i }); value != i {
+			t.Errorf("got %d, want %d", value, i)
+		}
+		if _, ok := c.Peek(key); ok {
+			t.Errorf("%s stored in the full cache", key)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := c.Peek("hot:" + strconv.Itoa(i)); !ok {
+			t.Errorf("hot:%d evicted", i)
+		}
+	}
+}
+
+func TestGracefulFullAppliesAllowIf(t *testing.T) {
+	c, err := NewCacheWithGracefulFullCache(WithAllowIf(func(_ string, value int) bool { return value > 0 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.LoadOrStore("zero", func() int { return 0 })
+	if _, ok := c.Peek("zero"); ok {
+		t.Error("value rejected by WithAllowIf cached")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// KeyHashDistributionCache reports how the hashes ristretto uses for the
+// cached keys spread over a number of buckets, e.g. to check whether keys
+// would concentrate on a few shards.
+type KeyHashDistributionCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+}
+
+func NewCacheWithKeyHashDistribution[K comparable, T any](opts ...Option[K, T]) (*KeyHashDistributionCache[K, T], error) {
+	c := &KeyHashDistributionCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// HashDistribution returns the number of cached keys per bucket, buckets
+// without keys are left out.
+func (c *KeyHashDistributionCache[K, T]) HashDistribution(buckets int) (map[int]int, error) {
+	if buckets <= 0 {
+		return nil, fmt.Errorf("number of buckets must be positive, got %d", buckets)
+	}
+	distribution := make(map[int]int)
+	c.entries.Range(func(key, _ any) bool {
+		hash, _ := keyToHash(key)
+		distribution[int(hash%uint64(buckets))]++
+		return true
+	})
+	return distribution, nil
+}
+
+// ExportHashDistributionCSV writes the number of cached keys of every bucket
+// as bucket,keys rows after a header row.
+func (c *KeyHashDistributionCache[K, T]) ExportHashDistributionCSV(w io.Writer, buckets int) error {
+	distribution, err := c.HashDistribution(buckets)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"bucket", "keys"}); err != nil {
+		return err
+	}
+	for bucket := 0; bucket < buckets; bucket++ {
+		if err := cw.Write([]string{strconv.Itoa(bucket), strconv.Itoa(distribution[bucket])}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
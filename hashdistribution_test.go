@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHashDistributionIsNearlyUniform(t *testing.T) {
+	c, err := NewCacheWithKeyHashDistribution[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const keys, buckets = 10000, 10
+	for i := 0; i < keys; i++ {
+		if !c.SetDefault("key:"+strconv.Itoa(i), i) {
+			t.Fatalf("set %d dropped", i)
+		}
+	}
+	distribution, err := c.HashDistribution(buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for bucket := 0; bucket < buckets; bucket++ {
+		// the expected count is 1000 with a standard deviation of about 30
+		if n := distribution[bucket]; n < 850 || n > 1150 {
+			t.Errorf("bucket %d holds %d of %d keys", bucket, n, keys)
+		}
+	}
+}
+
+func TestHashDistributionRejectsNonPositiveBuckets(t *testing.T) {
+	c, err := NewCacheWithKeyHashDistribution[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, buckets := range []int{0, -1} {
+		if _, err := c.HashDistribution(buckets); err == nil {
+			t.Errorf("%d buckets accepted", buckets)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// hashRing maps keys to nodes with consistent hashing, every node is placed
+// on the ring at several points to spread the keys evenly.
+type hashRing struct {
+	points []uint64
+	nodes  map[uint64]string
+}
+
+const ringReplicas = 100
+
+func newHashRing(nodes []string) *hashRing {
+	r := &hashRing{nodes: map[uint64]string{}}
+	for _, node := range nodes {
+		r.add(node)
+	}
+	return r
+}
+
+func (r *hashRing) add(node string) {
+	for i := 0; i < ringReplicas; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(node + "#" + strconv.Itoa(i)))
+		// FNV-1a hardly changes the high bits for names differing in
+		// their last bytes, mix them like the key hashes
+		point := mix64(h.Sum64())
+		r.points = append(r.points, point)
+		r.nodes[point] = node
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+}
+
+// node returns the node owning the key, the ring must not be empty.
+func (r *hashRing) node(key interface{}) string {
+	hash, _ := keyToHash(key)
+	hash = mix64(hash)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= hash
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.nodes[r.points[i]]
+}
+
+func (r *hashRing) remove(node string) {
+	points := r.points[:0]
+	for _, point := range r.points {
+		if r.nodes[point] == node {
+			delete(r.nodes, point)
+			continue
+		}
+		points = append(points, point)
+	}
+	r.points = points
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var ErrBackendUnhealthy = errors.New("backend is unhealthy")
+
+// WithHealthCheck probes the backend of the readers every interval.
+func WithHealthCheck[K comparable, T any](probe func() error, interval time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.healthProbe = probe
+		o.healthInterval = interval
+	}
+}
+
+// CacheWithHealthCheck does not invoke readers while the health probe fails.
+// Expired values kept because of WithStaleTTL are returned instead, keys
+// without such value fail with ErrBackendUnhealthy.
+type CacheWithHealthCheck[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	unhealthy atomic.Bool
+	stop      chan struct{}
+}
+
+func NewCacheWithHealthCheck[K comparable, T any](probe func() error, interval time.Duration, opts ...Option[K, T]) (*CacheWithHealthCheck[K, T], error) {
+	cache, err := NewCache(append(opts, WithHealthCheck[K, T](probe, interval))...)
+	if err != nil {
+		return nil, err
+	}
+	c := &CacheWithHealthCheck[K, T]{
+		ristrettoCache: cache,
+		stop:           make(chan struct{}),
+	}
+	go c.probe()
+	return c, nil
+}
+
+func (c *CacheWithHealthCheck[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *CacheWithHealthCheck[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if !c.unhealthy.Load() {
+		return c.ristrettoCache.LoadOrStoreE(key, read)
+	}
+
+	if value, ok := c.getStale(key); ok {
+		return value.value, nil
+	}
+	var zero T
+	return zero, ErrBackendUnhealthy
+}
+
+func (c *CacheWithHealthCheck[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *CacheWithHealthCheck[K, T]) probe() {
+	ticker := time.NewTicker(c.healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.unhealthy.Store(c.healthProbe() != nil)
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckSkipsReadersWhileUnhealthy(t *testing.T) {
+	c, err := NewCacheWithHealthCheck[string, string](func() error {
+		return errors.New("down")
+	}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for !c.unhealthy.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("probe did not mark the backend unhealthy")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	read := reader[string](func() string {
+		t.Error("reader invoked while the backend is unhealthy")
+		return "v"
+	})
+	if value := c.LoadOrStore("k", read); value != "" {
+		t.Errorf("LoadOrStore returned %q", value)
+	}
+	if _, err := c.LoadOrStoreE("k", read.withError()); !errors.Is(err, ErrBackendUnhealthy) {
+		t.Errorf("got %v, want %v", err, ErrBackendUnhealthy)
+	}
+}
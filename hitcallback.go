@@ -0,0 +1,23 @@
+package main
+
+// WithHitCallback calls fn with every value found in the cache before it is
+// returned to the caller, slow callbacks delay the callers.
+func WithHitCallback[K comparable, T any](fn func(key K, value T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.onHit = fn
+		o.asyncOnHit = false
+	}
+}
+
+// WithAsyncHitCallback calls fn with every value found in the cache in its
+// own goroutine.
+func WithAsyncHitCallback[K comparable, T any](fn func(key K, value T)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.onHit = fn
+		o.asyncOnHit = true
+	}
+}
+
+func NewCacheWithHitCallback[K comparable, T any](fn func(key K, value T), opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithHitCallback(fn))...)
+}
@@ -0,0 +1,86 @@
+package main
+
+import "sync"
+
+const defaultHitRateWindow = 1000
+
+// WithRollingHitRateObserver calls fn with the hit rate of the last
+// windowSize LoadOrStore calls, once every windowSize calls unless
+// WithHitRateObserverInterval sets another interval. The observer runs in
+// the calling goroutine.
+func WithRollingHitRateObserver[K comparable, T any](windowSize int, fn func(rate float64)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.hitRateWindow = windowSize
+		o.hitRateObserver = fn
+	}
+}
+
+// WithHitRateObserverInterval calls the hit rate observer every n LoadOrStore
+// calls.
+func WithHitRateObserverInterval[K comparable, T any](n int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.hitRateEvery = n
+	}
+}
+
+func NewCacheWithObservedHitRate[K comparable, T any](observer func(hitRate float64), opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append([]Option[K, T]{WithRollingHitRateObserver[K, T](defaultHitRateWindow, observer)}, opts...)...)
+}
+
+// rollingHitRate keeps the outcome of the last operations in a ring buffer
+// along with the number of hits among them.
+type rollingHitRate struct {
+	mu     sync.Mutex
+	ring   []bool
+	next   int
+	filled int
+	hits   int
+	every  int
+	ops    int
+}
+
+func newRollingHitRate(window, every int) *rollingHitRate {
+	if window <= 0 {
+		window = defaultHitRateWindow
+	}
+	if every <= 0 {
+		every = window
+	}
+	return &rollingHitRate{ring: make([]bool, window), every: every}
+}
+
+// record adds the outcome of an operation, it returns the rolling hit rate
+// and true when the observer is due.
+func (r *rollingHitRate) record(hit bool) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.filled == len(r.ring) {
+		if r.ring[r.next] {
+			r.hits--
+		}
+	} else {
+		r.filled++
+	}
+	r.ring[r.next] = hit
+	if hit {
+		r.hits++
+	}
+	r.next = (r.next + 1) % len(r.ring)
+
+	r.ops++
+	if r.ops < r.every {
+		return 0, false
+	}
+	r.ops = 0
+	return float64(r.hits) / float64(r.filled), true
+}
+
+func (c *ristrettoCache[K, T]) observeHit(hit bool) {
+	if c.hitRate == nil {
+		return
+	}
+	if rate, ok := c.hitRate.record(hit); ok {
+		c.hitRateObserver(rate)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// hotKeyCounters is the number of keys HotKeyDetector keeps counters for.
+const hotKeyCounters = 1024
+
+// WithHotKeyResetInterval sets how often the counters of the hot key
+// detector are reset, every minute by default.
+func WithHotKeyResetInterval[K comparable, T any](interval time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.hotKeyReset = interval
+	}
+}
+
+// HotKeyDetector finds the most frequently accessed keys with the
+// Space-Saving algorithm: it counts accesses of a fixed number of keys and
+// an untracked key replaces the least frequent one, taking over its count.
+// Estimates may be too high by the count taken over but never too low.
+// Counters are kept in buckets of equal counts, so every access takes
+// constant time.
+type HotKeyDetector[K comparable] struct {
+	mu       sync.Mutex
+	counters map[K]*hotKeyCounter[K]
+	// buckets holds *hotKeyBucket in ascending order of their counts
+	buckets *list.List
+}
+
+type hotKeyCounter[K comparable] struct {
+	key    K
+	bucket *list.Element
+}
+
+type hotKeyBucket[K comparable] struct {
+	count    uint64
+	counters map[*hotKeyCounter[K]]struct{}
+}
+
+func NewHotKeyDetector[K comparable]() *HotKeyDetector[K] {
+	return &HotKeyDetector[K]{
+		counters: make(map[K]*hotKeyCounter[K]),
+		buckets:  list.New(),
+	}
+}
+
+// Record counts an access of the key and returns its estimated frequency.
+func (d *HotKeyDetector[K]) Record(key K) uint64 {
+	_, count := d.record(key)
+	return count
+}
+
+// record returns the estimated frequency of the key before and after the
+// access, untracked keys had none before.
+func (d *HotKeyDetector[K]) record(key K) (before, after uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counter, ok := d.counters[key]
+	if !ok {
+		if len(d.counters) < hotKeyCounters {
+			counter = &hotKeyCounter[K]{key: key}
+			d.counters[key] = counter
+			return 0, d.increment(counter, nil)
+		}
+		// replace a key of the lowest count
+		least := d.buckets.Front()
+		for counter = range least.Value.(*hotKeyBucket[K]).counters {
+			break
+		}
+		delete(d.counters, counter.key)
+		counter.key = key
+		d.counters[key] = counter
+		return 0, d.increment(counter, counter.bucket)
+	}
+	count := d.increment(counter, counter.bucket)
+	return count - 1, count
+}
+
+// HotKeys returns up to n of the most frequently accessed keys, most
+// frequent first.
+func (d *HotKeyDetector[K]) HotKeys(n int) []K {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys := make([]K, 0, n)
+	for elem := d.buckets.Back(); elem != nil && len(keys) < n; elem = elem.Prev() {
+		for counter := range elem.Value.(*hotKeyBucket[K]).counters {
+			if len(keys) == n {
+				break
+			}
+			keys = append(keys, counter.key)
+		}
+	}
+	return keys
+}
+
+// Reset drops all counters.
+func (d *HotKeyDetector[K]) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.counters = make(map[K]*hotKeyCounter[K])
+	d.buckets.Init()
+}
+
+// increment moves the counter from its bucket, nil for new counters, to the
+// bucket of the next count and returns that count.
+func (d *HotKeyDetector[K]) increment(counter *hotKeyCounter[K], from *list.Element) uint64 {
+	var count uint64
+	next := d.buckets.Front()
+	if from != nil {
+		b := from.Value.(*hotKeyBucket[K])
+		count, next = b.count, from.Next()
+		delete(b.counters, counter)
+		if len(b.counters) == 0 {
+			d.buckets.Remove(from)
+		}
+	}
+	count++
+
+	if next == nil || next.Value.(*hotKeyBucket[K]).count != count {
+		b := &hotKeyBucket[K]{count: count, counters: make(map[*hotKeyCounter[K]]struct{})}
+		if next == nil {
+			next = d.buckets.PushBack(b)
+		} else {
+			next = d.buckets.InsertBefore(b, next)
+		}
+	}
+	next.Value.(*hotKeyBucket[K]).counters[counter] = struct{}{}
+	counter.bucket = next
+	return count
+}
+
+// HotKeyCache calls action for keys whose estimated number of LoadOrStore
+// calls within the reset interval reaches threshold, usually once per
+// interval, again if the key lost its counter meanwhile. The action runs in
+// the calling goroutine.
+type HotKeyCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	*HotKeyDetector[K]
+	threshold uint64
+	action    func(key K)
+	stop      chan struct{}
+}
+
+func NewCacheWithHotKeyDetector[K comparable, T any](threshold int, action func(K), opts ...Option[K, T]) (*HotKeyCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{WithHotKeyResetInterval[K, T](time.Minute)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &HotKeyCache[K, T]{
+		ristrettoCache: cache,
+		HotKeyDetector: NewHotKeyDetector[K](),
+		threshold:      uint64(threshold),
+		action:         action,
+		stop:           make(chan struct{}),
+	}
+	go c.reset()
+	return c, nil
+}
+
+func (c *HotKeyCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *HotKeyCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if before, after := c.record(key); before < c.threshold && after >= c.threshold {
+		c.action(key)
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *HotKeyCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *HotKeyCache[K, T]) reset() {
+	ticker := time.NewTicker(c.hotKeyReset)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Reset()
+		case <-c.stop:
+			return
+		}
+	}
+}
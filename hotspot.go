@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// hotspotThreshold is the number of accesses within the hot key reset
+// interval from which HotspotMitigationCache replicates a key.
+const hotspotThreshold = 1000
+
+// WithReplicas sets the number of replicas HotspotMitigationCache stores hot
+// keys in.
+func WithReplicas[K comparable, T any](n int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.replicas = n
+	}
+}
+
+// replicaKey is the key of a replica of a HotspotMitigationCache entry,
+// replica 0 hashes like the key itself.
+type replicaKey[K comparable] struct {
+	key     K
+	replica int
+}
+
+func (k replicaKey[K]) keyToHash() (uint64, uint64) {
+	h1, h2 := keyToHash(k.key)
+	if k.replica == 0 {
+		return h1, h2
+	}
+	return mix64(h1 + uint64(k.replica)), h2 ^ uint64(k.replica)
+}
+
+// HotspotMitigationCache spreads keys with extreme traffic over several
+// replicas, so their readers do not all contend on the same ristretto shard.
+// Keys accessed more than 1000 times within the hot key reset interval are
+// read from a random replica, every replica loads its value on its own.
+// Set and Delete update all replicas. The access counts and the set of hot
+// keys are reset every interval.
+type HotspotMitigationCache[K comparable, T any] struct {
+	cache  *ristrettoCache[replicaKey[K], T]
+	sketch *countMinSketch
+	// hot holds the replicated keys
+	hot  sync.Map
+	stop chan struct{}
+}
+
+func NewCacheWithHotspotMitigation[K comparable, T any](replicas int, opts ...Option[replicaKey[K], T]) (*HotspotMitigationCache[K, T], error) {
+	cache, err := NewCache(append(append([]Option[replicaKey[K], T]{
+		WithHotKeyResetInterval[replicaKey[K], T](time.Minute),
+	}, opts...), WithReplicas[replicaKey[K], T](replicas))...)
+	if err != nil {
+		return nil, err
+	}
+	cache.replicas = max(cache.replicas, 1)
+	c := &HotspotMitigationCache[K, T]{
+		cache:  cache,
+		sketch: newCountMinSketch(sketchDepth, sketchWidth),
+		stop:   make(chan struct{}),
+	}
+	go c.reset()
+	return c, nil
+}
+
+func (c *HotspotMitigationCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.cache.LoadOrStore(c.replica(key), read)
+}
+
+func (c *HotspotMitigationCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	return c.cache.LoadOrStoreE(c.replica(key), read)
+}
+
+func (c *HotspotMitigationCache[K, T]) Peek(key K) (T, bool) {
+	return c.cache.Peek(c.replica(key))
+}
+
+// SetDefault stores the value in all replicas of the key.
+func (c *HotspotMitigationCache[K, T]) SetDefault(key K, value T) {
+	for i := 0; i < c.cache.replicas; i++ {
+		c.cache.SetDefault(replicaKey[K]{key: key, replica: i}, value)
+	}
+}
+
+// Delete deletes all replicas of the key.
+func (c *HotspotMitigationCache[K, T]) Delete(key K) {
+	for i := 0; i < c.cache.replicas; i++ {
+		c.cache.Delete(replicaKey[K]{key: key, replica: i})
+	}
+}
+
+// IsHot reports whether the key is currently replicated.
+func (c *HotspotMitigationCache[K, T]) IsHot(key K) bool {
+	_, ok := c.hot.Load(key)
+	return ok
+}
+
+func (c *HotspotMitigationCache[K, T]) Close() {
+	close(c.stop)
+	c.cache.Close()
+}
+
+// replica counts the access of the key and returns the key of the replica
+// to access. Hot keys are not counted anymore, so their accesses do not
+// contend on the sketch.
+func (c *HotspotMitigationCache[K, T]) replica(key K) replicaKey[K] {
+	if _, ok := c.hot.Load(key); ok {
+		return replicaKey[K]{key: key, replica: rand.Intn(c.cache.replicas)}
+	}
+	if c.cache.replicas > 1 && c.sketch.increment(key) >= hotspotThreshold {
+		c.hot.Store(key, struct{}{})
+	}
+	return replicaKey[K]{key: key}
+}
+
+func (c *HotspotMitigationCache[K, T]) reset() {
+	ticker := time.NewTicker(c.cache.hotKeyReset)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.sketch.reset()
+			c.hot.Range(func(key, _ any) bool {
+				c.hot.Delete(key)
+				return true
+			})
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHotspotReplicatesHotKeys(t *testing.T) {
+	c, err := NewCacheWithHotspotMitigation[string, int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < hotspotThreshold*2; i++ {
+		if value := c.LoadOrStore("config", func() int { return 1 }); value != 1 {
+			t.Fatalf("got %d from a replica", value)
+		}
+	}
+	if !c.IsHot("config") {
+		t.Error("key loaded 2000 times not replicated")
+	}
+	if c.LoadOrStore("cold", func() int { return 2 }); c.IsHot("cold") {
+		t.Error("key loaded once replicated")
+	}
+}
+
+func benchmarkHotKey(b *testing.B, load func(key string, read reader[int]) int) {
+	read := func() int { return 1 }
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			load("config", read)
+		}
+	})
+}
+
+func BenchmarkHotKey(b *testing.B) {
+	b.Run("ristretto", func(b *testing.B) {
+		c, err := NewCache[string, int]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkHotKey(b, c.LoadOrStore)
+	})
+	for _, replicas := range []int{1, 8} {
+		b.Run("replicas="+strconv.Itoa(replicas), func(b *testing.B) {
+			c, err := NewCacheWithHotspotMitigation[string, int](replicas)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer c.Close()
+			benchmarkHotKey(b, c.LoadOrStore)
+		})
+	}
+}
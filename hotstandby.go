@@ -0,0 +1,168 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// standbyProbeInterval is how often HotStandbyCache checks ristretto, a probe
+// not answered within the interval counts as a failure.
+const standbyProbeInterval = 100 * time.Millisecond
+
+// WithStandby sets the cache HotStandbyCache fails over to.
+func WithStandby[K comparable, T any](standby Cache[K, T]) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.standby = standby
+	}
+}
+
+// HotStandbyCache fails over to the standby cache when ristretto's
+// goroutines stop responding. Once ristretto responds again, the keys stored
+// in or deleted from the standby meanwhile are moved back, replacing the
+// values ristretto held before the failover.
+type HotStandbyCache[K comparable, T any] struct {
+	primary  *ristrettoCache[K, T]
+	promoted atomic.Bool
+	stop     chan struct{}
+
+	mu sync.Mutex
+	// standbyKeys holds the keys stored in the standby while it is promoted,
+	// false for keys deleted meanwhile
+	standbyKeys map[K]bool
+}
+
+func NewCacheWithHotStandby[K comparable, T any](standby Cache[K, T], opts ...Option[K, T]) (*HotStandbyCache[K, T], error) {
+	primary, err := NewCache(append([]Option[K, T]{WithStandby(standby)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &HotStandbyCache[K, T]{
+		primary:     primary,
+		stop:        make(chan struct{}),
+		standbyKeys: make(map[K]bool),
+	}
+	go c.probe()
+	return c, nil
+}
+
+func (c *HotStandbyCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if c.promoted.Load() && c.track(key, true) {
+		return c.primary.standby.LoadOrStore(key, read)
+	}
+	return c.primary.LoadOrStore(key, read)
+}
+
+func (c *HotStandbyCache[K, T]) Peek(key K) (T, bool) {
+	if !c.promoted.Load() {
+		return c.primary.Peek(key)
+	}
+	return c.primary.standby.Peek(key)
+}
+
+func (c *HotStandbyCache[K, T]) SetDefault(key K, value T) bool {
+	if c.promoted.Load() && c.track(key, true) {
+		return c.primary.standby.SetDefault(key, value)
+	}
+	return c.primary.SetDefault(key, value)
+}
+
+// Delete deletes the key from the standby while it is promoted, ristretto
+// deletes it once it serves again. Otherwise the key is deleted from both
+// caches.
+func (c *HotStandbyCache[K, T]) Delete(key K) {
+	c.primary.standby.Delete(key)
+	if c.promoted.Load() && c.track(key, false) {
+		return
+	}
+	c.primary.Delete(key)
+}
+
+// Promoted reports whether the standby serves the operations.
+func (c *HotStandbyCache[K, T]) Promoted() bool {
+	return c.promoted.Load()
+}
+
+func (c *HotStandbyCache[K, T]) Close() {
+	close(c.stop)
+	c.primary.Close()
+}
+
+// track records the key as stored in or deleted from the standby, it
+// returns false if the standby is not promoted anymore.
+func (c *HotStandbyCache[K, T]) track(key K, stored bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.promoted.Load() {
+		return false
+	}
+	c.standbyKeys[key] = stored
+	return true
+}
+
+func (c *HotStandbyCache[K, T]) probe() {
+	ticker := time.NewTicker(standbyProbeInterval)
+	defer ticker.Stop()
+
+	// answered is closed once ristretto processed the pending probe, a probe
+	// still pending is not sent again
+	var answered chan struct{}
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		}
+
+		if answered == nil {
+			answered = make(chan struct{})
+			go func(answered chan struct{}) {
+				c.primary.cache.Wait()
+				close(answered)
+			}(answered)
+		}
+
+		select {
+		case <-answered:
+			answered = nil
+			if c.promoted.Load() {
+				c.demote()
+			}
+		case <-time.After(standbyProbeInterval):
+			c.promoted.Store(true)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// demote moves the keys stored in the standby back to ristretto. Keys are
+// moved while the standby still serves, the keys tracked meanwhile are moved
+// after ristretto took over again.
+func (c *HotStandbyCache[K, T]) demote() {
+	c.mu.Lock()
+	keys := c.standbyKeys
+	c.standbyKeys = make(map[K]bool)
+	c.mu.Unlock()
+	c.moveBack(keys)
+
+	c.mu.Lock()
+	c.promoted.Store(false)
+	keys = c.standbyKeys
+	c.standbyKeys = make(map[K]bool)
+	c.mu.Unlock()
+	c.moveBack(keys)
+}
+
+func (c *HotStandbyCache[K, T]) moveBack(keys map[K]bool) {
+	for key, stored := range keys {
+		if value, ok := c.primary.standby.Peek(key); ok && stored {
+			c.primary.SetDefault(key, value)
+		} else {
+			// deleted or evicted from the standby, the value ristretto holds
+			// is from before the failover
+			c.primary.Delete(key)
+		}
+		c.primary.standby.Delete(key)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHotStandbyMovesWritesBackOnDemote(t *testing.T) {
+	standby, err := NewCache[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer standby.Close()
+	c, err := NewCacheWithHotStandby[string, string](standby)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("updated", "old")
+	c.SetDefault("deleted", "old")
+
+	// fail over, the probe demotes the standby again as ristretto responds
+	c.promoted.Store(true)
+	c.SetDefault("updated", "new")
+	c.Delete("deleted")
+	if value, _ := c.Peek("updated"); value != "new" {
+		t.Errorf("standby serves %q", value)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for c.Promoted() {
+		if time.Now().After(deadline) {
+			t.Fatal("standby was not demoted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if value, _ := c.Peek("updated"); value != "new" {
+		t.Errorf("got %q after demote, want the value stored during failover", value)
+	}
+	if value, ok := c.Peek("deleted"); ok {
+		t.Errorf("key deleted during failover came back with %q", value)
+	}
+	if _, ok := standby.Peek("updated"); ok {
+		t.Error("moved key left in the standby")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxAgeLimit is the largest max-age directive in seconds, larger values are
+// treated as this one as RFC 9111 recommends.
+const maxAgeLimit = 1 << 31
+
+// HTTPResponseCache caches values read from HTTP responses for as long as
+// the max-age directive of their Cache-Control header allows. Responses
+// without max-age are cached for the default TTL, responses with max-age=0
+// are not cached.
+type HTTPResponseCache[K ~string, T any] struct {
+	*ristrettoCache[K, T]
+	// maxAges holds a *time.Duration per key whose miss read a response with
+	// max-age
+	maxAges sync.Map
+}
+
+func NewCacheWithDynamicTTLFromHeader[K ~string, T any](opts ...Option[K, T]) (*HTTPResponseCache[K, T], error) {
+	c := &HTTPResponseCache[K, T]{}
+	cache, err := NewCache(append(opts, WithAllowIf(func(key K, _ T) bool {
+		ttl, ok := c.maxAges.Load(key)
+		return !ok || *ttl.(*time.Duration) > 0
+	}), func(o *options[K, T]) {
+		o.ttlOf = func(e *entry[K, T]) time.Duration {
+			if ttl, ok := c.maxAges.Load(e.key); ok {
+				return *ttl.(*time.Duration)
+			}
+			return e.ttl
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// LoadOrStoreResponse returns the cached value or reads it with read, which
+// also returns the response the value was decoded from.
+func (c *HTTPResponseCache[K, T]) LoadOrStoreResponse(key K, read func() (T, *http.Response, error)) (T, error) {
+	var pending *time.Duration
+	// the miss holds the lock of the key, so only its own max-age is looked
+	// up while its value is stored
+	defer func() {
+		if pending != nil {
+			c.maxAges.CompareAndDelete(key, pending)
+		}
+	}()
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, resp, err := read()
+		if ttl, ok := maxAge(resp); ok {
+			pending = &ttl
+			c.maxAges.Store(key, pending)
+		} else {
+			c.maxAges.Delete(key)
+		}
+		return value, err
+	}, func() (T, error) {
+		value, _, err := read()
+		return value, err
+	})
+	return value, err
+}
+
+// maxAge returns the max-age directive of the response's Cache-Control
+// header.
+func maxAge(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	for _, header := range resp.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(header, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if !strings.EqualFold(name, "max-age") {
+				continue
+			}
+			seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+			if errors.Is(err, strconv.ErrRange) && seconds > 0 {
+				seconds = maxAgeLimit
+			} else if err != nil || seconds < 0 {
+				return 0, false
+			}
+			return time.Duration(min(seconds, maxAgeLimit)) * time.Second, true
+		}
+	}
+	return 0, false
+}
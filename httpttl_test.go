@@ -0,0 +1,100 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPResponseCacheUsesMaxAge(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if maxAge := r.URL.Query().Get("max-age"); maxAge != "" {
+			w.Header().Set("Cache-Control", "public, max-age="+maxAge)
+		}
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	c, err := NewCacheWithDynamicTTLFromHeader[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	load := func(url string) string {
+		value, err := c.LoadOrStoreResponse(url, func() (string, *http.Response, error) {
+			resp, err := http.Get(server.URL + url)
+			if err != nil {
+				return "", nil, err
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			return string(body), resp, err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	for _, tt := range []struct {
+		url string
+		// ttl is the TTL the response is cached with, 0 if it is not cached
+		ttl time.Duration
+	}{
+		{"/hour?max-age=3600", time.Hour},
+		{"/minute?max-age=60", time.Minute},
+		{"/uncached?max-age=0", 0},
+		{"/default", c.defaultTTL},
+	} {
+		requests.Store(0)
+		load(tt.url)
+		load(tt.url)
+		if want := map[bool]int32{true: 1, false: 2}[tt.ttl > 0]; requests.Load() != want {
+			t.Errorf("%s: %d requests for two loads, want %d", tt.url, requests.Load(), want)
+		}
+		if tt.ttl == 0 {
+			continue
+		}
+		if ttl, ok := c.cache.GetTTL(tt.url); !ok || ttl > tt.ttl || ttl < tt.ttl-time.Minute/2 {
+			t.Errorf("%s: cached for %s, want %s", tt.url, ttl, tt.ttl)
+		}
+	}
+}
+
+func TestMaxAgeIsClamped(t *testing.T) {
+	for _, header := range []string{"max-age=99999999999", "max-age=99999999999999999999999"} {
+		resp := &http.Response{Header: http.Header{"Cache-Control": {header}}}
+		if ttl, ok := maxAge(resp); !ok || ttl != maxAgeLimit*time.Second {
+			t.Errorf("%s: got %s, %v, want %s", header, ttl, ok, maxAgeLimit*time.Second)
+		}
+	}
+}
+
+func TestHTTPResponseCacheCountsHits(t *testing.T) {
+	var hits atomic.Int32
+	c, err := NewCacheWithDynamicTTLFromHeader(
+		WithSlidingExpiration[string, string](true),
+		WithHitCallback(func(string, string) { hits.Add(1) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	read := func() (string, *http.Response, error) {
+		return "v", &http.Response{Header: http.Header{"Cache-Control": {"max-age=60"}}}, nil
+	}
+	c.LoadOrStoreResponse("k", read)
+	c.LoadOrStoreResponse("k", read)
+	if n := hits.Load(); n != 1 {
+		t.Errorf("%d hits, want 1", n)
+	}
+	if e, ok := c.get("k"); !ok || e.ttl != time.Minute {
+		t.Error("renewed entry lost its max-age")
+	}
+}
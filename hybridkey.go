@@ -0,0 +1,89 @@
+package main
+
+// KeyKind tells which field of a HybridKey holds the key.
+type KeyKind int
+
+const (
+	IntKey KeyKind = iota
+	StringKey
+)
+
+// HybridKey is either an int or a string key.
+type HybridKey struct {
+	Int  int
+	Str  string
+	Kind KeyKind
+}
+
+func IntHybridKey(key int) HybridKey {
+	return HybridKey{Int: key, Kind: IntKey}
+}
+
+func StringHybridKey(key string) HybridKey {
+	return HybridKey{Str: key, Kind: StringKey}
+}
+
+// HybridCache caches values under int and string keys in separate ristretto
+// instances, so an int key never collides with a string key, e.g. while
+// moving from int to string keys.
+type HybridCache[T any] struct {
+	ints    *ristrettoCache[int, T]
+	strings *ristrettoCache[string, T]
+}
+
+func NewCacheWithHybridKeyEncoding[T any]() (*HybridCache[T], error) {
+	ints, err := NewCache[int, T]()
+	if err != nil {
+		return nil, err
+	}
+	strings, err := NewCache[string, T]()
+	if err != nil {
+		ints.Close()
+		return nil, err
+	}
+	return &HybridCache[T]{ints: ints, strings: strings}, nil
+}
+
+func (c *HybridCache[T]) LoadOrStore(key HybridKey, read reader[T]) T {
+	if key.Kind == StringKey {
+		return c.strings.LoadOrStore(key.Str, read)
+	}
+	return c.ints.LoadOrStore(key.Int, read)
+}
+
+func (c *HybridCache[T]) Peek(key HybridKey) (T, bool) {
+	if key.Kind == StringKey {
+		return c.strings.Peek(key.Str)
+	}
+	return c.ints.Peek(key.Int)
+}
+
+func (c *HybridCache[T]) SetDefault(key HybridKey, value T) bool {
+	if key.Kind == StringKey {
+		return c.strings.SetDefault(key.Str, value)
+	}
+	return c.ints.SetDefault(key.Int, value)
+}
+
+func (c *HybridCache[T]) Delete(key HybridKey) {
+	if key.Kind == StringKey {
+		c.strings.Delete(key.Str)
+		return
+	}
+	c.ints.Delete(key.Int)
+}
+
+// MigrateKey stores the value cached under from also under to, it returns
+// false if from is not cached or the value was not stored.
+func (c *HybridCache[T]) MigrateKey(from, to HybridKey) bool {
+	value, ok := c.Peek(from)
+	if !ok {
+		return false
+	}
+	return c.SetDefault(to, value)
+}
+
+func (c *HybridCache[T]) Close() {
+	c.ints.Close()
+	c.strings.Close()
+}
@@ -0,0 +1,194 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: cacheentry.proto
+
+package cachepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CacheEntry is a sample message for the tests of ProtoCached.
+type CacheEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Types that are assignable to Payload:
+	//	*CacheEntry_Text
+	//	*CacheEntry_Number
+	Payload isCacheEntry_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *CacheEntry) Reset() {
+	*x = CacheEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cacheentry_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheEntry) ProtoMessage() {}
+
+func (x *CacheEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_cacheentry_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheEntry.ProtoReflect.Descriptor instead.
+func (*CacheEntry) Descriptor() ([]byte, []int) {
+	return file_cacheentry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CacheEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (m *CacheEntry) GetPayload() isCacheEntry_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *CacheEntry) GetText() string {
+	if x, ok := x.GetPayload().(*CacheEntry_Text); ok {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *CacheEntry) GetNumber() int64 {
+	if x, ok := x.GetPayload().(*CacheEntry_Number); ok {
+		return x.Number
+	}
+	return 0
+}
+
+type isCacheEntry_Payload interface {
+	isCacheEntry_Payload()
+}
+
+type CacheEntry_Text struct {
+	Text string `protobuf:"bytes,2,opt,name=text,proto3,oneof"`
+}
+
+type CacheEntry_Number struct {
+	Number int64 `protobuf:"varint,3,opt,name=number,proto3,oneof"`
+}
+
+func (*CacheEntry_Text) isCacheEntry_Payload() {}
+
+func (*CacheEntry_Number) isCacheEntry_Payload() {}
+
+var File_cacheentry_proto protoreflect.FileDescriptor
+
+var file_cacheentry_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x63, 0x61, 0x63, 0x68, 0x65, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x09, 0x63, 0x61, 0x63, 0x68, 0x65, 0x74, 0x65, 0x73, 0x74, 0x22, 0x59, 0x0a,
+	0x0a, 0x43, 0x61, 0x63, 0x68, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x74,
+	0x65, 0x78, 0x74, 0x12, 0x18, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x09, 0x0a,
+	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x33, 0x5a, 0x31, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x61, 0x6b, 0x75, 0x62, 0x74, 0x6f, 0x6d, 0x61,
+	0x6e, 0x79, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x74, 0x65, 0x73, 0x74, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_cacheentry_proto_rawDescOnce sync.Once
+	file_cacheentry_proto_rawDescData = file_cacheentry_proto_rawDesc
+)
+
+func file_cacheentry_proto_rawDescGZIP() []byte {
+	file_cacheentry_proto_rawDescOnce.Do(func() {
+		file_cacheentry_proto_rawDescData = protoimpl.X.CompressGZIP(file_cacheentry_proto_rawDescData)
+	})
+	return file_cacheentry_proto_rawDescData
+}
+
+var file_cacheentry_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_cacheentry_proto_goTypes = []interface{}{
+	(*CacheEntry)(nil), // 0: cachetest.CacheEntry
+}
+var file_cacheentry_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_cacheentry_proto_init() }
+func file_cacheentry_proto_init() {
+	if File_cacheentry_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_cacheentry_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_cacheentry_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*CacheEntry_Text)(nil),
+		(*CacheEntry_Number)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_cacheentry_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_cacheentry_proto_goTypes,
+		DependencyIndexes: file_cacheentry_proto_depIdxs,
+		MessageInfos:      file_cacheentry_proto_msgTypes,
+	}.Build()
+	File_cacheentry_proto = out.File
+	file_cacheentry_proto_rawDesc = nil
+	file_cacheentry_proto_goTypes = nil
+	file_cacheentry_proto_depIdxs = nil
+}
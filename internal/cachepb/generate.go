@@ -0,0 +1,4 @@
+// Package cachepb holds the sample messages of the tests of ProtoCached.
+package cachepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative cacheentry.proto
@@ -0,0 +1,104 @@
+package main
+
+import "sync"
+
+// interned is a value shared by the cached entries holding it.
+type interned[T comparable] struct {
+	ptr  *T
+	refs int
+}
+
+// InterningCache stores equal values only once, all keys caching an equal
+// value share the same pointer. Interned values are dropped once no cached
+// entry holds them anymore. Callers must not modify the returned values.
+type InterningCache[K comparable, T comparable] struct {
+	cache *ristrettoCache[K, *T]
+
+	mu       sync.Mutex
+	interned map[T]*interned[T]
+}
+
+func NewCacheWithValueInterning[K comparable, T comparable](opts ...Option[K, *T]) (*InterningCache[K, T], error) {
+	c := &InterningCache[K, T]{interned: make(map[T]*interned[T])}
+	cache, err := NewCache(append(opts, func(o *options[K, *T]) {
+		o.onSet = func(e *entry[K, *T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if i, ok := c.interned[*e.value]; ok && i.ptr == e.value {
+				i.refs++
+			}
+		}
+		o.onExit = func(e *entry[K, *T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if i, ok := c.interned[*e.value]; ok && i.ptr == e.value {
+				// updates call onExit with the replaced entry before onSet
+				// is called with the new one, which may hold the same value
+				if i.refs--; i.refs <= 0 && !c.storedAgain(e) {
+					delete(c.interned, *e.value)
+				}
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.cache = cache
+	return c, nil
+}
+
+// NewCacheWithRequestCollapsingByValue returns an InterningCache, readers of
+// different keys producing equal values end up sharing one stored value.
+func NewCacheWithRequestCollapsingByValue[K comparable, T comparable](opts ...Option[K, *T]) (*InterningCache[K, T], error) {
+	return NewCacheWithValueInterning[K, T](opts...)
+}
+
+// LoadOrStore returns the interned pointer of the value.
+func (c *InterningCache[K, T]) LoadOrStore(key K, read reader[T]) *T {
+	value, _, _ := c.cache.loadOrStoreSource(key, func() (*T, error) {
+		return c.intern(read()), nil
+	}, nil)
+	return value
+}
+
+func (c *InterningCache[K, T]) Peek(key K) (*T, bool) {
+	return c.cache.Peek(key)
+}
+
+func (c *InterningCache[K, T]) SetDefault(key K, value T) bool {
+	return c.cache.SetDefault(key, c.intern(value))
+}
+
+func (c *InterningCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+// Interned returns the number of distinct values held by the cache.
+func (c *InterningCache[K, T]) Interned() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interned)
+}
+
+func (c *InterningCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+// storedAgain reports whether the key of the entry is stored with the same
+// interned pointer.
+func (c *InterningCache[K, T]) storedAgain(e *entry[K, *T]) bool {
+	stored, ok := c.cache.stored(e.key)
+	return ok && stored.value == e.value
+}
+
+func (c *InterningCache[K, T]) intern(value T) *T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if i, ok := c.interned[value]; ok {
+		return i.ptr
+	}
+	// the entry storing the value takes the first reference
+	c.interned[value] = &interned[T]{ptr: &value}
+	return &value
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+type internedConfig struct {
+	Region  string
+	Retries int
+}
+
+func TestInterningSharesEqualValues(t *testing.T) {
+	c, err := NewCacheWithValueInterning[string, internedConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	read := func() internedConfig { return internedConfig{Region: "eu", Retries: 3} }
+	a := c.LoadOrStore("a", read)
+	b := c.LoadOrStore("b", read)
+	if a != b {
+		t.Errorf("equal values have different pointers %p and %p", a, b)
+	}
+	if n := c.Interned(); n != 1 {
+		t.Errorf("%d values interned, want 1", n)
+	}
+}
+
+func TestInterningSurvivesStoringAnEqualValueAgain(t *testing.T) {
+	c, err := NewCacheWithValueInterning[string, internedConfig]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	value := internedConfig{Region: "eu", Retries: 3}
+	c.SetDefault("a", value)
+	// replacing the only entry holding the value must keep it interned
+	c.SetDefault("a", value)
+	a, _ := c.Peek("a")
+	b := c.LoadOrStore("b", func() internedConfig { return value })
+	if a != b {
+		t.Errorf("equal values have different pointers %p and %p", a, b)
+	}
+
+	c.Delete("a")
+	c.Delete("b")
+	c.cache.cache.Wait()
+	if n := c.Interned(); n != 0 {
+		t.Errorf("%d values interned after deleting all keys", n)
+	}
+}
+
+func TestRequestCollapsingByValueStoresIdenticalValuesOnce(t *testing.T) {
+	const n = 1000
+	c, err := NewCacheWithRequestCollapsingByValue[string, [4096]byte]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < n; i++ {
+		c.LoadOrStore(strconv.Itoa(i), func() (value [4096]byte) {
+			value[0] = 1
+			return value
+		})
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if interned := c.Interned(); interned != 1 {
+		t.Errorf("%d values interned, want 1", interned)
+	}
+	// storing every value separately would take n*4KiB, the entries
+	// themselves only take a fraction of that
+	if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > n*4096/4 {
+		t.Errorf("heap grew by %d bytes for %d identical values of 4KiB", grown, n)
+	}
+}
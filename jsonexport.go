@@ -0,0 +1,126 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WithBearerToken sets the token JSONExportCache requires in the
+// Authorization header of every request to its handler.
+func WithBearerToken[K comparable, T any](token string) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.bearerToken = token
+	}
+}
+
+// JSONExportCache serves its entries over HTTP as JSON, so operators can
+// inspect and fix cached values in production. Without a bearer token all
+// requests are refused.
+type JSONExportCache[K ~string, T any] struct {
+	*ristrettoCache[K, T]
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+}
+
+func NewCacheWithJSONHTTPExport[K ~string, T any](opts ...Option[K, T]) (*JSONExportCache[K, T], error) {
+	c := &JSONExportCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// Handler serves the cache under /cache:
+//
+//	GET /cache          lists the cached keys
+//	GET /cache/{key}    returns the cached value
+//	POST /cache/{key}   stores the JSON body as the value with the default TTL
+//	DELETE /cache/{key} deletes the key
+func (c *JSONExportCache[K, T]) Handler() http.Handler {
+	return http.HandlerFunc(c.serveHTTP)
+}
+
+func (c *JSONExportCache[K, T]) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !c.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/cache")
+	if path == "" || path == "/" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, c.keys())
+		return
+	}
+	key, ok := strings.CutPrefix(path, "/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, ok := c.Peek(K(key))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, value)
+	case http.MethodPost:
+		var value T
+		if err := json.NewDecoder(r.Body).Decode(&value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !c.SetDefault(K(key), value) {
+			http.Error(w, "value was not stored", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		c.Delete(K(key))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (c *JSONExportCache[K, T]) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && c.bearerToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.bearerToken)) == 1
+}
+
+func (c *JSONExportCache[K, T]) keys() []K {
+	keys := []K{}
+	c.entries.Range(func(key, e any) bool {
+		if !e.(*entry[K, T]).expired(c.staleTTL) {
+			keys = append(keys, key.(K))
+		}
+		return true
+	})
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "sync"
+
+// WithKeyCanonicalizer maps equivalent keys to the same canonical key before
+// KeyDeduplicationCache looks them up, e.g. queries with sorted parameters.
+func WithKeyCanonicalizer[K comparable, T any](fn func(key K) K) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.canonicalize = fn
+	}
+}
+
+// WithDeduplicationBudget limits the number of keys KeyDeduplicationCache
+// remembers the canonical keys of, 65536 by default. Keys beyond the budget
+// are canonicalized on every access.
+func WithDeduplicationBudget[K comparable, T any](maxKeys int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.dedupBudget = maxKeys
+	}
+}
+
+// KeyDeduplicationCache stores values under the canonical keys set with
+// WithKeyCanonicalizer, so equivalent keys share an entry.
+type KeyDeduplicationCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu        sync.RWMutex
+	canonical map[K]K
+}
+
+func NewCacheWithRequestDeduplication[K comparable, T any](opts ...Option[K, T]) (*KeyDeduplicationCache[K, T], error) {
+	defaults := []Option[K, T]{
+		WithKeyCanonicalizer[K, T](func(key K) K { return key }),
+		WithDeduplicationBudget[K, T](1 << 16),
+	}
+	cache, err := NewCache(append(defaults, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyDeduplicationCache[K, T]{
+		ristrettoCache: cache,
+		canonical:      make(map[K]K),
+	}, nil
+}
+
+func (c *KeyDeduplicationCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.ristrettoCache.LoadOrStore(c.canonicalKey(key), read)
+}
+
+func (c *KeyDeduplicationCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	return c.ristrettoCache.LoadOrStoreE(c.canonicalKey(key), read)
+}
+
+func (c *KeyDeduplicationCache[K, T]) Peek(key K) (T, bool) {
+	return c.ristrettoCache.Peek(c.canonicalKey(key))
+}
+
+func (c *KeyDeduplicationCache[K, T]) SetDefault(key K, value T) bool {
+	return c.ristrettoCache.SetDefault(c.canonicalKey(key), value)
+}
+
+func (c *KeyDeduplicationCache[K, T]) Delete(key K) {
+	c.ristrettoCache.Delete(c.canonicalKey(key))
+}
+
+func (c *KeyDeduplicationCache[K, T]) canonicalKey(key K) K {
+	c.mu.RLock()
+	canonical, ok := c.canonical[key]
+	c.mu.RUnlock()
+	if ok {
+		return canonical
+	}
+
+	canonical = c.canonicalize(key)
+	c.mu.Lock()
+	if len(c.canonical) < c.dedupBudget {
+		c.canonical[key] = canonical
+	}
+	c.mu.Unlock()
+	return canonical
+}
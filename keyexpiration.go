@@ -0,0 +1,64 @@
+package main
+
+// ExpiryReason tells why an entry left the cache.
+type ExpiryReason int
+
+const (
+	TTLExpired ExpiryReason = iota
+	ManualDelete
+	CostEviction
+)
+
+func (r ExpiryReason) String() string {
+	switch r {
+	case TTLExpired:
+		return "ttl expired"
+	case ManualDelete:
+		return "manual delete"
+	case CostEviction:
+		return "cost eviction"
+	}
+	return "unknown"
+}
+
+// CacheEvent describes an entry which left the cache.
+type CacheEvent[K comparable, T any] struct {
+	Key    K
+	Value  T
+	Reason ExpiryReason
+}
+
+// KeyExpirationCache calls the handler for entries which expired, were
+// evicted or deleted. Expirations and evictions are reported from
+// ristretto's goroutine, deletes from the goroutine calling Delete. Deletes
+// of keys which are not cached are not reported.
+type KeyExpirationCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	handler func(event CacheEvent[K, T])
+}
+
+func NewCacheWithKeyExpiration[K comparable, T any](handler func(event CacheEvent[K, T]), opts ...Option[K, T]) (*KeyExpirationCache[K, T], error) {
+	c := &KeyExpirationCache[K, T]{handler: handler}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onEvicted = func(e *entry[K, T]) {
+			reason := CostEviction
+			if e.expired(c.staleTTL) {
+				reason = TTLExpired
+			}
+			c.handler(CacheEvent[K, T]{Key: e.key, Value: e.value, Reason: reason})
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *KeyExpirationCache[K, T]) Delete(key K) {
+	e, ok := c.getStale(key)
+	c.ristrettoCache.Delete(key)
+	if ok {
+		c.handler(CacheEvent[K, T]{Key: e.key, Value: e.value, Reason: ManualDelete})
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+)
+
+var ErrInvalidKey = errors.New("key does not match the key schema")
+
+// WithKeySchema rejects keys not matching the pattern. LoadOrStoreE returns
+// ErrInvalidKey for them without calling the reader and SetDefault does not
+// store them.
+func WithKeySchema[K ~string, T any](pattern *regexp.Regexp) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.keySchema = func(key K) bool {
+			return pattern.MatchString(string(key))
+		}
+	}
+}
+
+func NewCacheWithEnforceKeySchema[K ~string, T any](schema *regexp.Regexp, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithKeySchema[K, T](schema))...)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestKeySchema(t *testing.T) {
+	c, err := NewCacheWithEnforceKeySchema[string, string](regexp.MustCompile(`^[a-z]+:[0-9]+$`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, key := range []string{"user:1", "order:42"} {
+		if _, err := c.LoadOrStoreE(key, func() (string, error) { return "v", nil }); err != nil {
+			t.Errorf("valid key %q: %v", key, err)
+		}
+	}
+	for _, key := range []string{"1", "user:", "User:1", "user:1x", ":1"} {
+		value, err := c.LoadOrStoreE(key, func() (string, error) {
+			t.Errorf("reader called for invalid key %q", key)
+			return "v", nil
+		})
+		if !errors.Is(err, ErrInvalidKey) || value != "" {
+			t.Errorf("invalid key %q: got %q, %v, want %v", key, value, err, ErrInvalidKey)
+		}
+		if c.SetDefault(key, "v") {
+			t.Errorf("invalid key %q stored", key)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "time"
+
+// LazyExpiryCache stores entries without a TTL in ristretto and checks their
+// age when they are accessed instead, entries older than the default TTL are
+// deleted and count as misses. Expired entries which are not accessed again
+// stay in the cache until ristretto evicts them for their cost.
+type LazyExpiryCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	ttl time.Duration
+}
+
+func NewCacheWithLazyExpiry[K comparable, T any](opts ...Option[K, T]) (*LazyExpiryCache[K, T], error) {
+	c := &LazyExpiryCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.ttlOf = func(*entry[K, T]) time.Duration {
+			return 0
+		}
+		o.isFresh = func(e *entry[K, T]) bool {
+			if time.Since(e.computedAt) < c.ttl {
+				return true
+			}
+			c.cache.Del(e.key)
+			return false
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	c.ttl = cache.defaultTTL
+	return c, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLazyExpiryDeletesExpiredEntriesOnAccess(t *testing.T) {
+	c, err := NewCacheWithLazyExpiry[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.ttl = 50 * time.Millisecond
+
+	c.SetDefault("k", 1)
+	time.Sleep(100 * time.Millisecond)
+	// ristretto holds the expired entry until it is accessed
+	if _, ok := c.cache.Get("k"); !ok {
+		t.Fatal("entry removed before it was accessed")
+	}
+
+	if _, ok := c.Peek("k"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, ok := c.cache.Get("k"); ok {
+		t.Error("expired entry kept after it was accessed")
+	}
+	if value := c.LoadOrStore("k", func() int { return 2 }); value != 2 {
+		t.Errorf("got %d, want the value read again", value)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// leaseTimeout is the time after which a lease which has not been completed
+// is handed out again.
+const leaseTimeout = 10 * time.Second
+
+// Lease is the right to read a missing value and store it with Complete.
+type Lease[K comparable] struct {
+	Key       K
+	expiresAt time.Time
+}
+
+// LeaseCache prevents stampedes with leases: on a miss only one caller gets a
+// lease and reads the value, the others are told the value is loading and
+// retry later. Deleting a key invalidates its lease, so a value read before
+// the delete is not stored.
+type LeaseCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu     sync.Mutex
+	leases map[K]*Lease[K]
+}
+
+func NewCacheWithLeaseBasedCoordination[K comparable, T any](opts ...Option[K, T]) (*LeaseCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &LeaseCache[K, T]{
+		ristrettoCache: cache,
+		leases:         make(map[K]*Lease[K]),
+	}, nil
+}
+
+// GetOrLease returns the cached value and true on hits. On misses it returns
+// a lease to the first caller, the caller must read the value and pass it to
+// Complete. Other callers get neither a value nor a lease while the value is
+// loading and should retry after a backoff.
+func (c *LeaseCache[K, T]) GetOrLease(key K) (T, *Lease[K], bool) {
+	if value, ok := c.Peek(key); ok {
+		return value, nil, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero T
+	if lease, ok := c.leases[key]; ok && time.Now().Before(lease.expiresAt) {
+		return zero, nil, false
+	}
+	lease := &Lease[K]{Key: key, expiresAt: time.Now().Add(leaseTimeout)}
+	c.leases[key] = lease
+	return zero, lease, false
+}
+
+// Complete stores the value read for the lease and reports whether it was
+// stored. Values of leases which expired or were invalidated by Delete are
+// not stored.
+func (c *LeaseCache[K, T]) Complete(lease *Lease[K], value T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.leases[lease.Key] != lease {
+		return false
+	}
+	delete(c.leases, lease.Key)
+	if !time.Now().Before(lease.expiresAt) {
+		return false
+	}
+	return c.SetDefault(lease.Key, value)
+}
+
+func (c *LeaseCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.leases, key)
+	c.mu.Unlock()
+
+	c.ristrettoCache.Delete(key)
+}
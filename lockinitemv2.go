@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+func NewLockInItemCacheV2[K comparable, T any]() *lockInItemCacheV2[K, T] {
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1e7,     // number of keys to track frequency of (10M).
+		MaxCost:     1 << 30, // maximum cost of cache (1GB).
+		BufferItems: 64,      // number of keys per Get buffer.
+		KeyToHash:   keyToHash,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &lockInItemCacheV2[K, T]{
+		cache: cache,
+	}
+}
+
+// lockInItemCacheV2 works like lockInItemCache, but it does not wait for
+// ristretto to apply its sets, the items are shared through pending in the
+// meantime.
+type lockInItemCacheV2[K comparable, T any] struct {
+	cache   *ristretto.Cache
+	pending sync.Map
+}
+
+func (c *lockInItemCacheV2[K, T]) LoadOrStore(key K, read reader[T]) T {
+	item := c.item(key)
+	if value := item.value.Load(); value != nil {
+		return value.value
+	}
+
+	item.lock.Lock()
+	defer item.lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value := item.value.Load(); value != nil {
+		return value.value
+	}
+
+	// read the value with reader and store it in the cache
+	value := &itemValue[T]{
+		value:     read(),
+		expiresAt: time.Now().Add(ttl),
+	}
+	item.value.Store(value)
+	c.cache.SetWithTTL(key, item, 1, ttl)
+
+	return value.value
+}
+
+func (c *lockInItemCacheV2[K, T]) item(key K) *lockedItem[T] {
+	if entry, ok := c.cache.Get(key); ok {
+		// ristretto has the item, the pending one is not needed anymore
+		item := entry.(*lockedItem[T])
+		c.pending.CompareAndDelete(key, item)
+		return item
+	}
+
+	for {
+		entry, _ := c.pending.LoadOrStore(key, &lockedItem[T]{})
+		item := entry.(*lockedItem[T])
+		// the set was dropped by ristretto or the item has already expired
+		if value := item.value.Load(); value != nil && time.Now().After(value.expiresAt) {
+			c.pending.CompareAndDelete(key, item)
+			continue
+		}
+		return item
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockInItemCacheV2ReadsOnceUnderContention(t *testing.T) {
+	c := NewLockInItemCacheV2[int, int]()
+	defer c.cache.Close()
+
+	var reads atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value := c.LoadOrStore(1, func() int {
+				reads.Add(1)
+				time.Sleep(time.Millisecond)
+				return 42
+			})
+			if value != 42 {
+				t.Errorf("got %d, want 42", value)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := reads.Load(); n != 1 {
+		t.Errorf("reader called %d times", n)
+	}
+}
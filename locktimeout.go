@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrLockTimeout = errors.New("timed out waiting for the key lock")
+
+// LockTimeoutCache gives up waiting for the lock of a key after the timeout,
+// so callers do not pile up behind a slow reader. The per-key locks are
+// channels with a buffer of one, which can be acquired with a timeout.
+type LockTimeoutCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	timeout time.Duration
+	chLocks sync.Map
+}
+
+func NewCacheWithLockTimeout[K comparable, T any](timeout time.Duration, opts ...Option[K, T]) (*LockTimeoutCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &LockTimeoutCache[K, T]{
+		ristrettoCache: cache,
+		timeout:        timeout,
+	}, nil
+}
+
+func (c *LockTimeoutCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE waits for the lock of the key only on misses and returns
+// ErrLockTimeout if it is not acquired within the timeout.
+func (c *LockTimeoutCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if _, ok := c.get(key); !ok {
+		anyLock, _ := c.chLocks.LoadOrStore(key, make(chan struct{}, 1))
+		lock := anyLock.(chan struct{})
+		timer := time.NewTimer(c.timeout)
+		select {
+		case lock <- struct{}{}:
+			timer.Stop()
+		case <-timer.C:
+			var zero T
+			return zero, ErrLockTimeout
+		}
+		defer func() { <-lock }()
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockTimeout(t *testing.T) {
+	c, err := NewCacheWithLockTimeout[string, string](10 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.LoadOrStore("k", func() string {
+			close(started)
+			<-release
+			return "slow"
+		})
+	}()
+	<-started
+
+	_, err = c.LoadOrStoreE("k", func() (string, error) { return "fast", nil })
+	if !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("got %v, want %v", err, ErrLockTimeout)
+	}
+	close(release)
+	<-done
+}
+
+func TestLockTimeoutSingleFlightAcrossLoadOrStoreAndLoadOrStoreE(t *testing.T) {
+	c, err := NewCacheWithLockTimeout[string, string](time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var reads atomic.Int32
+	read := func() (string, error) {
+		reads.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "v", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.LoadOrStore("k", func() string {
+				value, _ := read()
+				return value
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			c.LoadOrStoreE("k", read)
+		}()
+	}
+	wg.Wait()
+	if n := reads.Load(); n != 1 {
+		t.Errorf("reader called %d times", n)
+	}
+}
+
+// benchmarkContendedMisses reports the 99th percentile latency of
+// goroutines loading a key whose slow reader keeps missing.
+func benchmarkContendedMisses(b *testing.B, load func(key int, read readerE[int]) (int, error)) {
+	read := func() (int, error) {
+		time.Sleep(100 * time.Microsecond)
+		return 1, nil
+	}
+	latencies := make([]time.Duration, b.N)
+	var next atomic.Int64
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			start := time.Now()
+			load(1, read)
+			latencies[next.Add(1)-1] = time.Since(start)
+		}
+	})
+	b.StopTimer()
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+}
+
+func BenchmarkContendedMisses(b *testing.B) {
+	// values are never stored, so every call is a miss
+	never := WithAllowIf(func(int, int) bool { return false })
+	b.Run("mutex", func(b *testing.B) {
+		c, err := NewCache(never)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkContendedMisses(b, c.LoadOrStoreE)
+	})
+	b.Run("lock-timeout", func(b *testing.B) {
+		c, err := NewCacheWithLockTimeout(time.Millisecond, never)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkContendedMisses(b, c.LoadOrStoreE)
+	})
+}
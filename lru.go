@@ -0,0 +1,117 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUCache evicts the least recently used entry once it holds capacity
+// entries, unlike ristretto it never rejects new entries. Entries expire
+// after the default TTL like in the other caches.
+type LRUCache[K comparable, T any] struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[K]*list.Element
+
+	locks sync.Map
+}
+
+type lruEntry[K comparable, T any] struct {
+	key K
+	itemValue[T]
+}
+
+func NewCacheWithAccessOrderEviction[K comparable, T any](capacity int) *LRUCache[K, T] {
+	return &LRUCache[K, T]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element),
+	}
+}
+
+func (c *LRUCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock := anyLock.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	value := read()
+	c.SetDefault(key, value)
+	return value
+}
+
+// Peek returns the cached value and marks it as used.
+func (c *LRUCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	e := elem.Value.(*lruEntry[K, T])
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(elem)
+		var zero T
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return e.value, true
+}
+
+// SetDefault stores the value with the default TTL, evicting the least
+// recently used entry if the cache is full.
+func (c *LRUCache[K, T]) SetDefault(key K, value T) bool {
+	now := time.Now()
+	e := &lruEntry[K, T]{key: key, itemValue: itemValue[T]{value: value, computedAt: now, expiresAt: now.Add(ttl)}}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.order.MoveToFront(elem)
+		return true
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return true
+}
+
+func (c *LRUCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of cached entries including expired ones which
+// have not been removed yet.
+func (c *LRUCache[K, T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRUCache[K, T]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry[K, T]).key)
+}
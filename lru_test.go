@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// benchmarkKeys returns keys drawn from [0, n), uniformly or with a skewed
+// Zipfian distribution.
+func benchmarkKeys(n int, zipf bool) []int {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.1, 1, uint64(n-1))
+	keys := make([]int, 1<<16)
+	for i := range keys {
+		if zipf {
+			keys[i] = int(z.Uint64())
+		} else {
+			keys[i] = r.Intn(n)
+		}
+	}
+	return keys
+}
+
+// benchmarkLoads loads the keys in a loop and reports the hit ratio.
+func benchmarkLoads(b *testing.B, cache Cache[int, int], keys []int) {
+	var misses int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := keys[i&(len(keys)-1)]
+		cache.LoadOrStore(key, func() int {
+			misses++
+			return key
+		})
+	}
+	b.ReportMetric(1-float64(misses)/float64(b.N), "hit-ratio")
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCacheWithAccessOrderEviction[string, int](2)
+	c.SetDefault("a", 1)
+	c.SetDefault("b", 2)
+	c.Peek("a")
+	c.SetDefault("c", 3)
+
+	if _, ok := c.Peek("b"); ok {
+		t.Error("least recently used entry b kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Peek(key); !ok {
+			t.Errorf("entry %s evicted", key)
+		}
+	}
+}
+
+func BenchmarkLRU(b *testing.B) {
+	const capacity, keySpace = 1000, 10000
+	for _, dist := range []struct {
+		name string
+		zipf bool
+	}{{"uniform", false}, {"zipf", true}} {
+		keys := benchmarkKeys(keySpace, dist.zipf)
+		b.Run(dist.name+"/lru", func(b *testing.B) {
+			benchmarkLoads(b, NewCacheWithAccessOrderEviction[int, int](capacity), keys)
+		})
+		b.Run(dist.name+"/ristretto", func(b *testing.B) {
+			c, err := NewCache(WithMaxCost[int, int](capacity))
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer c.Close()
+			benchmarkLoads(b, c, keys)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// SnapshotStats describes a snapshot written by LZ4SnapshotCache.
+type SnapshotStats struct {
+	Entries          int
+	UncompressedSize int64
+	CompressedSize   int64
+	// Ratio is the uncompressed size divided by the compressed size.
+	Ratio float64
+}
+
+type snapshotRecord[K comparable, T any] struct {
+	Key       K
+	Value     T
+	ExpiresAt time.Time
+}
+
+// LZ4SnapshotCache can write its entries as an LZ4 compressed stream of gob
+// encoded records and restore them from it. Keys and values must be
+// encodable by gob.
+type LZ4SnapshotCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+}
+
+func NewCacheWithLZ4Snapshot[K comparable, T any](opts ...Option[K, T]) (*LZ4SnapshotCache[K, T], error) {
+	c := &LZ4SnapshotCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// Snapshot writes the entries which have not expired to w.
+func (c *LZ4SnapshotCache[K, T]) Snapshot(w io.Writer) (SnapshotStats, error) {
+	return c.snapshot(w, nil, nil)
+}
+
+// snapshot writes the extra records after the cached entries, they replace
+// cached entries of the same keys on Restore. If wait is not nil, it is
+// called before every cached entry and its error aborts the snapshot.
+func (c *LZ4SnapshotCache[K, T]) snapshot(w io.Writer, extra []snapshotRecord[K, T], wait func() error) (SnapshotStats, error) {
+	var stats SnapshotStats
+	compressed := &countingWriter{w: w}
+	zw := lz4.NewWriter(compressed)
+	uncompressed := &countingWriter{w: zw}
+	enc := gob.NewEncoder(uncompressed)
+
+	var err error
+	c.entries.Range(func(_, value any) bool {
+		e := value.(*entry[K, T])
+		if e.ttl > 0 && time.Now().After(e.expiresAt) {
+			return true
+		}
+		if wait != nil {
+			if err = wait(); err != nil {
+				return false
+			}
+		}
+		record := snapshotRecord[K, T]{Key: e.key, Value: e.value}
+		if e.ttl > 0 {
+			record.ExpiresAt = e.expiresAt
+		}
+		if err = enc.Encode(&record); err != nil {
+			return false
+		}
+		stats.Entries++
+		return true
+	})
+	if err != nil {
+		return stats, err
+	}
+	for i := range extra {
+		if err := enc.Encode(&extra[i]); err != nil {
+			return stats, err
+		}
+		stats.Entries++
+	}
+	if err := zw.Close(); err != nil {
+		return stats, err
+	}
+
+	stats.UncompressedSize = uncompressed.n
+	stats.CompressedSize = compressed.n
+	if stats.CompressedSize > 0 {
+		stats.Ratio = float64(stats.UncompressedSize) / float64(stats.CompressedSize)
+	}
+	return stats, nil
+}
+
+// Restore stores the entries of a snapshot read from r which have not
+// expired yet, keeping their expiration.
+func (c *LZ4SnapshotCache[K, T]) Restore(r io.Reader) error {
+	dec := gob.NewDecoder(lz4.NewReader(r))
+	for {
+		var record snapshotRecord[K, T]
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		if record.ExpiresAt.IsZero() {
+			c.setWithTTL(record.Key, record.Value, 0)
+		} else if ttl := time.Until(record.ExpiresAt); ttl > 0 {
+			c.setWithTTL(record.Key, record.Value, ttl)
+		}
+	}
+	c.cache.Wait()
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLZ4SnapshotRoundTrip(t *testing.T) {
+	c, err := NewCacheWithLZ4Snapshot[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const n = 2000
+	value := func(i int) string {
+		return strings.Repeat("value "+strconv.Itoa(i)+" ", 10)
+	}
+	for i := 0; i < n; i++ {
+		if !c.SetDefault("key:"+strconv.Itoa(i), value(i)) {
+			t.Fatalf("set %d dropped", i)
+		}
+	}
+	var snapshot bytes.Buffer
+	stats, err := c.Snapshot(&snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != n || stats.CompressedSize != int64(snapshot.Len()) || stats.Ratio <= 1 {
+		t.Errorf("got stats %+v for %d entries in %d bytes", stats, n, snapshot.Len())
+	}
+
+	restored, err := NewCacheWithLZ4Snapshot[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Close()
+	if err := restored.Restore(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if got, ok := restored.Peek("key:" + strconv.Itoa(i)); !ok || got != value(i) {
+			t.Fatalf("key %d restored as %q, %v", i, got, ok)
+		}
+	}
+}
@@ -2,31 +2,33 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
-	"github.com/google/uuid"
+	"github.com/jakubtomany/cachetest/cachebench"
 )
 
-type cacheItem[T any] struct {
+// lockedItem holds the lock of a key along with its value, which is read
+// without holding the lock.
+type lockedItem[T any] struct {
 	lock  sync.Mutex
-	value *itemValue[T]
+	value atomic.Pointer[itemValue[T]]
 }
 
 type itemValue[T any] struct {
-	value T
+	value      T
+	expiresAt  time.Time
+	computedAt time.Time
+	version    uint64
+	generation int
 }
 
 type reader[T any] func() T
 
-func readUUID(cnt *int, sleep time.Duration) func() string {
-	return func() string {
-		*cnt++
-		time.Sleep(sleep)
-		return uuid.NewString()
-	}
-}
+type readerE[T any] func() (T, error)
 
 func newLockInItemCache[T any]() *lockInItemCache[T] {
 	cache, err := ristretto.NewCache(&ristretto.Config{
@@ -42,44 +44,63 @@ func newLockInItemCache[T any]() *lockInItemCache[T] {
 	}
 }
 
+// lockInItemCache keeps the lock of a key in its ristretto item. Items are
+// kept in pending until ristretto returns them, so all goroutines lock the
+// same item even if ristretto drops or evicts it.
 type lockInItemCache[T any] struct {
-	cache *ristretto.Cache
+	cache   *ristretto.Cache
+	pending sync.Map
 }
 
 func (c *lockInItemCache[T]) LoadOrStore(key int, read reader[T]) T {
-	var item *cacheItem[T]
-
-	entry, ok := c.cache.Get(key)
-	if !ok {
-		// create cache entry with lock for the key
-		c.cache.SetWithTTL(key, &cacheItem[T]{}, 1, ttl)
-		c.cache.Wait()
-		entry, _ = c.cache.Get(key)
-	}
-
-	item = entry.(*cacheItem[T])
-	if item.value != nil {
-		return item.value.value
+	item := c.item(key)
+	if value := item.value.Load(); value != nil {
+		return value.value
 	}
 
 	item.lock.Lock()
 	defer item.lock.Unlock()
 
-	// make sure the value has not been set in the cache yet
-	entry, _ = c.cache.Get(key)
-	item = entry.(*cacheItem[T])
-	if item.value != nil {
-		return item.value.value
+	// make sure the value has not been set while waiting for the lock
+	if value := item.value.Load(); value != nil {
+		return value.value
 	}
 
 	// read the value with reader and store it in the cache
-	item.value = &itemValue[T]{
-		value: read(),
+	value := &itemValue[T]{
+		value:     read(),
+		expiresAt: time.Now().Add(ttl),
 	}
+	item.value.Store(value)
 	c.cache.SetWithTTL(key, item, 1, ttl)
 	c.cache.Wait()
 
-	return item.value.value
+	return value.value
+}
+
+func (c *lockInItemCache[T]) item(key int) *lockedItem[T] {
+	if entry, ok := c.cache.Get(key); ok {
+		// ristretto has the item, the pending one is not needed anymore
+		item := entry.(*lockedItem[T])
+		c.pending.CompareAndDelete(key, item)
+		return item
+	}
+
+	for {
+		entry, loaded := c.pending.LoadOrStore(key, &lockedItem[T]{})
+		item := entry.(*lockedItem[T])
+		// the set was dropped by ristretto or the item has already expired
+		if value := item.value.Load(); value != nil && time.Now().After(value.expiresAt) {
+			c.pending.CompareAndDelete(key, item)
+			continue
+		}
+		if !loaded {
+			// create cache entry with lock for the key
+			c.cache.SetWithTTL(key, item, 1, ttl)
+			c.cache.Wait()
+		}
+		return item
+	}
 }
 
 func newSyncMapCache[T any]() *syncMapCache[T] {
@@ -124,6 +145,29 @@ func (c *syncMapCache[T]) LoadOrStore(key int, read reader[T]) T {
 	return value.value
 }
 
+// GroupInvalidate deletes all keys while holding their locks, so none of them
+// can be loaded again before all of them are deleted.
+func (c *syncMapCache[T]) GroupInvalidate(keys []int) {
+	// lock in sorted order to avoid deadlocks with concurrent invalidations
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	locks := make([]*sync.Mutex, len(sorted))
+	for i, key := range sorted {
+		anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+		locks[i] = anyLock.(*sync.Mutex)
+		locks[i].Lock()
+	}
+	for _, key := range sorted {
+		c.cache.Del(key)
+	}
+	c.cache.Wait()
+	for _, lock := range locks {
+		lock.Unlock()
+	}
+}
+
 const (
 	rounds   = 100
 	routines = 1000
@@ -132,59 +176,38 @@ const (
 )
 
 func main() {
-	var wg sync.WaitGroup
+	result := cachebench.Benchmark(cachebench.BenchmarkConfig{
+		Rounds:   rounds,
+		Routines: routines,
+		Reps:     reps,
+		Sleeps:   []time.Duration{0, 10 * time.Millisecond, 100 * time.Millisecond, 300 * time.Millisecond},
+		Strategies: []cachebench.Strategy{
+			{Name: "lockInItemCache", New: func() cachebench.LoadOrStoreFunc {
+				c := newLockInItemCache[string]()
+				return func(key int, read func() string) string { return c.LoadOrStore(key, read) }
+			}},
+			{Name: "syncMapCache", New: func() cachebench.LoadOrStoreFunc {
+				c := newSyncMapCache[string]()
+				return func(key int, read func() string) string { return c.LoadOrStore(key, read) }
+			}},
+			{Name: "lockInItemCacheV2", New: func() cachebench.LoadOrStoreFunc {
+				c := NewLockInItemCacheV2[int, string]()
+				return func(key int, read func() string) string { return c.LoadOrStore(key, read) }
+			}},
+			{Name: "globalLockCache", New: func() cachebench.LoadOrStoreFunc {
+				c := NewCacheWithGlobalLock[int, string]()
+				return func(key int, read func() string) string { return c.LoadOrStore(key, read) }
+			}},
+		},
+	})
 
-	for _, slp := range []time.Duration{0, 10 * time.Millisecond, 100 * time.Millisecond, 300 * time.Millisecond} {
+	for _, sleep := range result.Sleeps {
 		fmt.Println("\n##################################")
-		fmt.Println("Starting with sleep", slp)
-		start := time.Now()
-
-		itmLckCache := newLockInItemCache[string]()
-		sncMapCache := newSyncMapCache[string]()
-
-		var ilCacheDur time.Duration = 0
-		var smCacheDur time.Duration = 0
-		ilReadCnt := 0
-		smReadCnt := 0
-
-		for round := 0; round < rounds; round++ {
-			//fmt.Println("\nRound ", round)
-
-			roundStart := time.Now()
-			for i := 0; i < routines; i++ {
-				wg.Add(1)
-				go func(i int) {
-					defer wg.Done()
-					for j := 0; j < reps; j++ {
-						itmLckCache.LoadOrStore(i, readUUID(&ilReadCnt, slp))
-					}
-				}(i)
-			}
-			wg.Wait()
-			dur := time.Since(roundStart)
-			ilCacheDur += dur
-			//fmt.Println("lockInItemCache: ", dur)
-
-			roundStart = time.Now()
-			for i := 0; i < routines; i++ {
-				wg.Add(1)
-				go func(i int) {
-					defer wg.Done()
-					for j := 0; j < reps; j++ {
-						sncMapCache.LoadOrStore(i, readUUID(&smReadCnt, slp))
-					}
-				}(i)
-			}
-			wg.Wait()
-			dur = time.Since(roundStart)
-			smCacheDur += dur
-			//fmt.Println("   syncMapCache: ", dur)
+		fmt.Println("Starting with sleep", sleep.Sleep)
+		for _, strategy := range sleep.Strategies {
+			fmt.Println("\n" + strategy.Name)
+			fmt.Println(" number of reads:", strategy.ReadCount, "\n duration:", strategy.Duration, "\n p99 latency:", strategy.P99Latency)
 		}
-
-		fmt.Println("\nlockInItemCache")
-		fmt.Println(" number of reads:", ilReadCnt, "\n duration:", ilCacheDur)
-		fmt.Println("\nsyncMapCache")
-		fmt.Println(" number of reads:", smReadCnt, "\n duration:", smCacheDur)
-		fmt.Println("\nTotal duration:", time.Since(start))
+		fmt.Println("\nTotal duration:", sleep.Duration)
 	}
 }
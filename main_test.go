@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+)
+
+func TestLockInItemCacheReadsOnceWhenSetsAreDropped(t *testing.T) {
+	cache, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: 1e4,
+		// every item costs more than the cache, so ristretto drops all sets
+		MaxCost:     -1,
+		BufferItems: 64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &lockInItemCache[int]{cache: cache}
+	defer c.cache.Close()
+
+	var reads atomic.Int32
+	read := func() int {
+		reads.Add(1)
+		time.Sleep(time.Millisecond)
+		return 42
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value := c.LoadOrStore(1, read); value != 42 {
+				t.Errorf("got %d, want 42", value)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, ok := c.cache.Get(1); ok {
+		t.Fatal("ristretto stored the item")
+	}
+	if value := c.LoadOrStore(1, read); value != 42 {
+		t.Errorf("got %d, want 42", value)
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("reader called %d times", n)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrNoKeys = errors.New("no keys to aggregate")
+
+// MapReduceCache caches results aggregated from several entries of an
+// underlying cache.
+type MapReduceCache[K comparable, T any, R any] struct {
+	values  *ristrettoCache[K, T]
+	results *ristrettoCache[string, R]
+}
+
+func NewMapReduceCache[K comparable, T any, R any](values *ristrettoCache[K, T]) (*MapReduceCache[K, T, R], error) {
+	results, err := NewCache[string, R]()
+	if err != nil {
+		return nil, err
+	}
+	return &MapReduceCache[K, T, R]{
+		values:  values,
+		results: results,
+	}, nil
+}
+
+// Aggregate loads all keys and reduces their values. The values are passed to
+// reduce in the order of the sorted keys and the result is cached under a key
+// derived from them, so the order of keys does not matter.
+func (c *MapReduceCache[K, T, R]) Aggregate(keys []K, reduce func(values []T) R, read reader[T]) (R, error) {
+	if len(keys) == 0 {
+		var zero R
+		return zero, ErrNoKeys
+	}
+
+	sorted := make([]K, len(keys))
+	copy(sorted, keys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return fmt.Sprint(sorted[i]) < fmt.Sprint(sorted[j])
+	})
+
+	return c.results.LoadOrStore(compoundKey(sorted), func() R {
+		return reduce(c.values.LoadOrStoreBatch(sorted, read))
+	}), nil
+}
+
+func compoundKey[K comparable](keys []K) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%#v", key)
+	}
+	return strings.Join(parts, "\x00")
+}
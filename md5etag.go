@@ -0,0 +1,36 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+)
+
+// MD5ETagCache works like ETagCache with the hex encoded MD5 hash of the gob
+// encoded value as ETag, which is what many HTTP clients expect.
+type MD5ETagCache[K ~string, T any] struct {
+	*ETagCache[K, T]
+}
+
+func NewCacheWithMD5ETag[K ~string, T any](opts ...Option[K, etagged[T]]) (*MD5ETagCache[K, T], error) {
+	cache, err := NewCacheWithETagSupport[K, T](opts...)
+	if err != nil {
+		return nil, err
+	}
+	cache.etag = md5ETag[T]
+	return &MD5ETagCache[K, T]{ETagCache: cache}, nil
+}
+
+// ValidateETag reports whether the key is cached with the client's ETag.
+func (c *MD5ETagCache[K, T]) ValidateETag(key K, clientETag string) bool {
+	_, etag, ok := c.Peek(key)
+	return ok && etag != "" && etag == clientETag
+}
+
+func md5ETag[T any](value T) string {
+	data, ok := gobEncode(value)
+	if !ok {
+		return ""
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
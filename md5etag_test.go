@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestMD5ETagKnownValues(t *testing.T) {
+	c, err := NewCacheWithMD5ETag[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// the MD5 sums of the gob encodings 08 0c 00 05 "hello" and 03 0c 00 00
+	for value, want := range map[string]string{
+		"hello": "943655f63dae695d2b5c0abaf629a6d6",
+		"":      "e4b434577ce1f1ea8145f22a04058585",
+	} {
+		key := "key:" + value
+		if _, etag, _ := c.LoadOrStoreWithETag(key, "", func() string { return value }); etag != want {
+			t.Errorf("ETag of %q is %s, want %s", value, etag, want)
+		}
+		if !c.ValidateETag(key, want) {
+			t.Errorf("ETag %s of %q not validated", want, value)
+		}
+		if c.ValidateETag(key, "d41d8cd98f00b204e9800998ecf8427e") {
+			t.Errorf("wrong ETag of %q validated", value)
+		}
+	}
+	if c.ValidateETag("missing", "943655f63dae695d2b5c0abaf629a6d6") {
+		t.Error("ETag of a missing key validated")
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+// MemoCache memoizes values in a plain map for single-threaded use, e.g. in
+// tests. Values never expire or get evicted, so the reader is called once
+// per key. MemoCache must not be used concurrently.
+type MemoCache[K comparable, T any] struct {
+	values map[K]*itemValue[T]
+}
+
+func NewCacheWithMemoization[K comparable, T any]() *MemoCache[K, T] {
+	return &MemoCache[K, T]{values: make(map[K]*itemValue[T])}
+}
+
+func (c *MemoCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.values[key]; ok {
+		return value.value
+	}
+	value := read()
+	c.values[key] = &itemValue[T]{value: value}
+	return value
+}
+
+func (c *MemoCache[K, T]) Peek(key K) (T, bool) {
+	value, ok := c.values[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return value.value, true
+}
+
+func (c *MemoCache[K, T]) SetDefault(key K, value T) bool {
+	c.values[key] = &itemValue[T]{value: value}
+	return true
+}
+
+func (c *MemoCache[K, T]) Delete(key K) {
+	delete(c.values, key)
+}
+
+// Reset forgets all memoized values.
+func (c *MemoCache[K, T]) Reset() {
+	clear(c.values)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// LoadOrStoreFunc returns the value of the key like LoadOrStore and reports
+// whether it was cached.
+type LoadOrStoreFunc[K comparable, T any] func(key K, read reader[T]) (value T, hit bool)
+
+// Middleware wraps LoadOrStore the way http middleware wraps handlers.
+type Middleware[K comparable, T any] func(next LoadOrStoreFunc[K, T]) LoadOrStoreFunc[K, T]
+
+// Use adds middleware to LoadOrStore, the first middleware passed to Use is
+// the outermost one. Use must not be called concurrently with LoadOrStore.
+func (c *ristrettoCache[K, T]) Use(mw ...Middleware[K, T]) {
+	c.middleware = append(c.middleware, mw...)
+	c.chain = c.loadOrStore
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		c.chain = c.middleware[i](c.chain)
+	}
+}
+
+// LoggingMiddleware logs every LoadOrStore with its duration at the debug
+// level.
+func LoggingMiddleware[K comparable, T any](logger *slog.Logger) Middleware[K, T] {
+	return func(next LoadOrStoreFunc[K, T]) LoadOrStoreFunc[K, T] {
+		return func(key K, read reader[T]) (T, bool) {
+			start := time.Now()
+			value, hit := next(key, read)
+			logger.LogAttrs(context.Background(), slog.LevelDebug, "cache operation",
+				slog.Any("key", key),
+				slog.String("operation", "LoadOrStore"),
+				slog.Duration("duration", time.Since(start)),
+				slog.Bool("hit", hit),
+			)
+			return value, hit
+		}
+	}
+}
+
+// MetricsMiddleware calls record with the outcome and duration of every
+// LoadOrStore.
+func MetricsMiddleware[K comparable, T any](record func(key K, hit bool, duration time.Duration)) Middleware[K, T] {
+	return func(next LoadOrStoreFunc[K, T]) LoadOrStoreFunc[K, T] {
+		return func(key K, read reader[T]) (T, bool) {
+			start := time.Now()
+			value, hit := next(key, read)
+			record(key, hit, time.Since(start))
+			return value, hit
+		}
+	}
+}
+
+// RetryMiddleware calls a panicking reader again, up to attempts times in
+// total. The panic of the last attempt is propagated.
+func RetryMiddleware[K comparable, T any](attempts int) Middleware[K, T] {
+	return func(next LoadOrStoreFunc[K, T]) LoadOrStoreFunc[K, T] {
+		return func(key K, read reader[T]) (T, bool) {
+			return next(key, func() T {
+				for i := 1; i < attempts; i++ {
+					if value, ok := tryRead(read); ok {
+						return value
+					}
+				}
+				return read()
+			})
+		}
+	}
+}
+
+func tryRead[T any](read reader[T]) (value T, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return read(), true
+}
+
+// TracingMiddleware calls start before every LoadOrStore and the function it
+// returns afterwards, e.g. to start and end a tracing span.
+func TracingMiddleware[K comparable, T any](start func(key K) (end func(hit bool))) Middleware[K, T] {
+	return func(next LoadOrStoreFunc[K, T]) LoadOrStoreFunc[K, T] {
+		return func(key K, read reader[T]) (T, bool) {
+			end := start(key)
+			value, hit := next(key, read)
+			end(hit)
+			return value, hit
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MinFrequencyCache stores values only once their key was missed
+// minFrequency times within the default TTL, values of keys accessed less
+// often are read on every access. This keeps keys accessed only once from
+// taking up space in the cache.
+type MinFrequencyCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	minFrequency uint32
+	sketch       *countMinSketch
+
+	mu          sync.Mutex
+	windowStart time.Time
+}
+
+func NewCacheWithMinFrequency[K comparable, T any](minFrequency int, opts ...Option[K, T]) (*MinFrequencyCache[K, T], error) {
+	if minFrequency < 1 {
+		return nil, fmt.Errorf("min frequency must be at least 1, got %d", minFrequency)
+	}
+	c := &MinFrequencyCache[K, T]{
+		minFrequency: uint32(minFrequency),
+		sketch:       newCountMinSketch(sketchDepth, sketchWidth),
+		windowStart:  time.Now(),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.invokeMiss = c.invokeMiss
+		o.storeMiss = c.storeMiss
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// invokeMiss counts the miss of the key.
+func (c *MinFrequencyCache[K, T]) invokeMiss(key K, read readerE[T]) (T, error) {
+	c.mu.Lock()
+	if time.Since(c.windowStart) > c.defaultTTL {
+		c.sketch.reset()
+		c.windowStart = time.Now()
+	}
+	c.mu.Unlock()
+
+	c.sketch.increment(key)
+	return c.invoke(read)
+}
+
+// storeMiss stores the entry once its key was missed often enough.
+func (c *MinFrequencyCache[K, T]) storeMiss(e *entry[K, T]) error {
+	if c.sketch.estimate(e.key) < c.minFrequency {
+		return nil
+	}
+	c.setEntry(e)
+	c.cache.Wait()
+	return nil
+}
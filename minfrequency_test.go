@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestMinFrequencyStoresKeysMissedOftenEnough(t *testing.T) {
+	c, err := NewCacheWithMinFrequency[string, int](3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	reads := 0
+	read := func() int {
+		reads++
+		return reads
+	}
+	for i := 1; i <= 3; i++ {
+		if value := c.LoadOrStore("k", read); value != i {
+			t.Fatalf("miss %d: got %d", i, value)
+		}
+	}
+	// the third miss stored the value
+	if value := c.LoadOrStore("k", read); value != 3 {
+		t.Errorf("got %d, want the value of the third miss", value)
+	}
+}
+
+func TestMinFrequencyAppliesKeySchemaToUncachedKeys(t *testing.T) {
+	c, err := NewCacheWithMinFrequency(5, WithKeySchema[string, int](regexp.MustCompile(`^valid$`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.LoadOrStoreE("invalid", func() (int, error) { return 1, nil }); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("got %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestMinFrequencyRejectsValuesBelowOne(t *testing.T) {
+	for _, minFrequency := range []int{0, -1} {
+		if _, err := NewCacheWithMinFrequency[string, int](minFrequency); err == nil {
+			t.Errorf("min frequency %d accepted", minFrequency)
+		}
+	}
+}
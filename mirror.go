@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+)
+
+// MirrorCache sends all calls to the primary and the mirror cache, it is
+// meant for trying out a new cache configuration alongside the current one.
+// Values returned by the mirror which differ from the primary are logged,
+// callers always get the value of the primary.
+type MirrorCache[K comparable, T any] struct {
+	primary Cache[K, T]
+	mirror  Cache[K, T]
+	eq      func(a, b T) bool
+	logger  *slog.Logger
+}
+
+func NewCacheWithMirror[K comparable, T any](primary, mirror Cache[K, T], eq func(a, b T) bool, logger *slog.Logger) *MirrorCache[K, T] {
+	return &MirrorCache[K, T]{
+		primary: primary,
+		mirror:  mirror,
+		eq:      eq,
+		logger:  logger,
+	}
+}
+
+func (c *MirrorCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	primary := make(chan T, 1)
+	go func() {
+		mirrored := c.mirror.LoadOrStore(key, read)
+		if value := <-primary; !c.eq(value, mirrored) {
+			c.logger.LogAttrs(context.Background(), slog.LevelWarn, "mirror cache returned a different value",
+				slog.Any("key", key),
+				slog.Any("primary", value),
+				slog.Any("mirror", mirrored),
+			)
+		}
+	}()
+
+	value := c.primary.LoadOrStore(key, read)
+	primary <- value
+	return value
+}
+
+func (c *MirrorCache[K, T]) Peek(key K) (T, bool) {
+	go c.mirror.Peek(key)
+	return c.primary.Peek(key)
+}
+
+func (c *MirrorCache[K, T]) SetDefault(key K, value T) bool {
+	go c.mirror.SetDefault(key, value)
+	return c.primary.SetDefault(key, value)
+}
+
+func (c *MirrorCache[K, T]) Delete(key K) {
+	go c.mirror.Delete(key)
+	c.primary.Delete(key)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// MutationLog records the values stored in and deleted from a cache.
+type MutationLog[K comparable, T any] interface {
+	LogSet(key K, value T, ttl time.Duration)
+	LogDelete(key K)
+}
+
+type mutationRecord[K comparable, T any] struct {
+	Seq   uint64        `json:"seq"`
+	Time  time.Time     `json:"time"`
+	Op    string        `json:"op"`
+	Key   K             `json:"key"`
+	Value *T            `json:"value,omitempty"`
+	TTL   time.Duration `json:"ttl,omitempty"`
+}
+
+// JSONMutationLog writes mutations as newline-delimited JSON. Records are
+// queued and written by a background goroutine, so logging only blocks when
+// the queue is full.
+type JSONMutationLog[K comparable, T any] struct {
+	records chan mutationRecord[K, T]
+	done    chan struct{}
+	err     error
+}
+
+func NewJSONMutationLog[K comparable, T any](w io.Writer) *JSONMutationLog[K, T] {
+	l := &JSONMutationLog[K, T]{
+		records: make(chan mutationRecord[K, T], 1024),
+		done:    make(chan struct{}),
+	}
+	go l.write(w)
+	return l
+}
+
+func (l *JSONMutationLog[K, T]) LogSet(key K, value T, ttl time.Duration) {
+	l.records <- mutationRecord[K, T]{Time: time.Now(), Op: "set", Key: key, Value: &value, TTL: ttl}
+}
+
+func (l *JSONMutationLog[K, T]) LogDelete(key K) {
+	l.records <- mutationRecord[K, T]{Time: time.Now(), Op: "delete", Key: key}
+}
+
+// Close writes the queued records and returns the first write error.
+func (l *JSONMutationLog[K, T]) Close() error {
+	close(l.records)
+	<-l.done
+	return l.err
+}
+
+func (l *JSONMutationLog[K, T]) write(w io.Writer) {
+	defer close(l.done)
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	var seq uint64
+	for record := range l.records {
+		// the sequence number follows the order of records in the log
+		seq++
+		record.Seq = seq
+		if err := enc.Encode(record); err != nil && l.err == nil {
+			l.err = err
+		}
+		// batch the records which are already queued into a single write
+		if len(l.records) == 0 {
+			if err := buf.Flush(); err != nil && l.err == nil {
+				l.err = err
+			}
+		}
+	}
+	if err := buf.Flush(); err != nil && l.err == nil {
+		l.err = err
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrOperationTimeout = errors.New("cache operation timed out")
+
+// OperationTimeoutCache bounds the duration of every operation including the
+// reader. Operations run in their own goroutine, when opTimeout passes the
+// caller gets ErrOperationTimeout while the operation keeps running in the
+// background.
+type OperationTimeoutCache[K comparable, T any] struct {
+	cache     *ristrettoCache[K, T]
+	opTimeout time.Duration
+}
+
+func NewCacheWithOperationTimeout[K comparable, T any](opTimeout time.Duration, opts ...Option[K, T]) (*OperationTimeoutCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &OperationTimeoutCache[K, T]{cache: cache, opTimeout: opTimeout}, nil
+}
+
+func (c *OperationTimeoutCache[K, T]) LoadOrStore(key K, read reader[T]) (T, error) {
+	return c.LoadOrStoreE(key, read.withError())
+}
+
+func (c *OperationTimeoutCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	var value T
+	var err error
+	if timeoutErr := c.run(func() { value, err = c.cache.LoadOrStoreE(key, read) }); timeoutErr != nil {
+		var zero T
+		return zero, timeoutErr
+	}
+	return value, err
+}
+
+func (c *OperationTimeoutCache[K, T]) Peek(key K) (T, bool, error) {
+	var value T
+	var ok bool
+	if err := c.run(func() { value, ok = c.cache.Peek(key) }); err != nil {
+		var zero T
+		return zero, false, err
+	}
+	return value, ok, nil
+}
+
+func (c *OperationTimeoutCache[K, T]) SetDefault(key K, value T) (bool, error) {
+	var ok bool
+	if err := c.run(func() { ok = c.cache.SetDefault(key, value) }); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+func (c *OperationTimeoutCache[K, T]) Delete(key K) error {
+	return c.run(func() { c.cache.Delete(key) })
+}
+
+func (c *OperationTimeoutCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+// run returns ErrOperationTimeout if op does not return in time, op must
+// only write variables the caller reads when run returns nil.
+func (c *OperationTimeoutCache[K, T]) run(op func()) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		op()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ErrOperationTimeout
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+// ErrReaderPanic is returned for readers which panicked, Cause holds the
+// value passed to panic.
+type ErrReaderPanic struct {
+	Cause any
+}
+
+func (e ErrReaderPanic) Error() string {
+	return fmt.Sprintf("reader panicked: %v", e.Cause)
+}
+
+// PanicRecoveringCache returns ErrReaderPanic for readers which panic instead
+// of propagating the panic. Nothing is cached for the key then and waiters
+// for the key's lock call their own reader.
+type PanicRecoveringCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithPanicRecovery[K comparable, T any](opts ...Option[K, T]) (*PanicRecoveringCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PanicRecoveringCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *PanicRecoveringCache[K, T]) LoadOrStore(key K, read reader[T]) (T, error) {
+	return c.LoadOrStoreE(key, read.withError())
+}
+
+func (c *PanicRecoveringCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	return c.ristrettoCache.LoadOrStoreE(key, func() (value T, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = ErrReaderPanic{Cause: r}
+			}
+		}()
+		return read()
+	})
+}
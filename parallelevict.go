@@ -0,0 +1,17 @@
+package main
+
+// ParallelEvictionCache runs the eviction callback on a pool of workers
+// instead of ristretto's goroutine, so evictions do not wait for each other.
+// Up to 1024 evictions are queued, further evictions are dropped and
+// counted in Stats.DroppedEvictions.
+type ParallelEvictionCache[K comparable, T any] struct {
+	*CacheWithEvictionQueue[K, T]
+}
+
+func NewCacheWithParallelEviction[K comparable, T any](workers int, opts ...Option[K, T]) (*ParallelEvictionCache[K, T], error) {
+	cache, err := NewCacheWithEvictionQueue(1024, append(opts, WithEvictionWorkers[K, T](workers))...)
+	if err != nil {
+		return nil, err
+	}
+	return &ParallelEvictionCache[K, T]{CacheWithEvictionQueue: cache}, nil
+}
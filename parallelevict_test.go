@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func BenchmarkEvictionCallbacks(b *testing.B) {
+	for _, workers := range []int{1, 8} {
+		b.Run("workers="+strconv.Itoa(workers), func(b *testing.B) {
+			var evicted atomic.Int64
+			c, err := NewCacheWithParallelEviction(workers,
+				WithMaxCost[int, int](100),
+				// a callback waiting on I/O, e.g. writing the entry elsewhere
+				WithOnEvict(func(int, int) {
+					time.Sleep(100 * time.Microsecond)
+					evicted.Add(1)
+				}),
+			)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.SetDefault(i, i)
+			}
+			dropped := c.Stats().DroppedEvictions
+			c.Close()
+			b.StopTimer()
+			b.ReportMetric(float64(evicted.Load())/float64(b.N), "evicted/op")
+			b.ReportMetric(float64(dropped)/float64(b.N), "dropped/op")
+		})
+	}
+}
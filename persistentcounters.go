@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithCounterInterval sets how often PersistentCounterCache writes its
+// counters to the file, every minute by default.
+func WithCounterInterval[K comparable, T any](interval time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.counterInterval = interval
+	}
+}
+
+// PersistentCounterCache counts the LoadOrStore calls per key and keeps the
+// counters across restarts in a gob encoded file. The file is replaced
+// atomically, a crash loses at most the counts since the last write. Keys
+// must be encodable by gob.
+type PersistentCounterCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	counterFile string
+	errors      atomic.Uint64
+	stop        chan struct{}
+	stopped     chan struct{}
+
+	mu     sync.Mutex
+	counts map[K]uint64
+}
+
+func NewCacheWithPersistentCounters[K comparable, T any](counterFile string, opts ...Option[K, T]) (*PersistentCounterCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{WithCounterInterval[K, T](time.Minute)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &PersistentCounterCache[K, T]{
+		ristrettoCache: cache,
+		counterFile:    counterFile,
+		stop:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+		counts:         make(map[K]uint64),
+	}
+	if err := c.restore(); err != nil {
+		cache.Close()
+		return nil, err
+	}
+
+	go c.persistLoop()
+	return c, nil
+}
+
+func (c *PersistentCounterCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *PersistentCounterCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	c.mu.Lock()
+	c.counts[key]++
+	c.mu.Unlock()
+
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+// Count returns the number of LoadOrStore calls for the key.
+func (c *PersistentCounterCache[K, T]) Count(key K) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[key]
+}
+
+// Persist writes the counters to the file right away.
+func (c *PersistentCounterCache[K, T]) Persist() error {
+	c.mu.Lock()
+	counts := make(map[K]uint64, len(c.counts))
+	for key, count := range c.counts {
+		counts[key] = count
+	}
+	c.mu.Unlock()
+
+	// write a temporary file which replaces the old one once it is complete
+	f, err := os.CreateTemp(filepath.Dir(c.counterFile), filepath.Base(c.counterFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := gob.NewEncoder(f).Encode(counts); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.counterFile)
+}
+
+// Stats reports failed periodic writes of the counter file as WriteErrors.
+func (c *PersistentCounterCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.WriteErrors = c.errors.Load()
+	return stats
+}
+
+// Close writes the counters a last time and closes the cache.
+func (c *PersistentCounterCache[K, T]) Close() error {
+	close(c.stop)
+	<-c.stopped
+	c.ristrettoCache.Close()
+	return c.Persist()
+}
+
+func (c *PersistentCounterCache[K, T]) restore() error {
+	f, err := os.Open(c.counterFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return gob.NewDecoder(f).Decode(&c.counts)
+}
+
+func (c *PersistentCounterCache[K, T]) persistLoop() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.counterInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.stop:
+			return
+		}
+		if err := c.Persist(); err != nil {
+			c.errors.Add(1)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistentCountersSurviveRestart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "counters.gob")
+	c, err := NewCacheWithPersistentCounters[string, int](file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		c.LoadOrStore("a", func() int { return 1 })
+	}
+	c.LoadOrStore("b", func() int { return 2 })
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the restarted cache starts empty but keeps counting
+	restarted, err := NewCacheWithPersistentCounters[string, int](file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+	if _, ok := restarted.Peek("a"); ok {
+		t.Error("value kept across the restart")
+	}
+	restarted.LoadOrStore("a", func() int { return 1 })
+	if a, b := restarted.Count("a"), restarted.Count("b"); a != 4 || b != 1 {
+		t.Errorf("got counts %d and %d, want 4 and 1", a, b)
+	}
+}
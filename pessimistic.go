@@ -0,0 +1,32 @@
+package main
+
+// PessimisticCache serializes all writers of a key on its key lock.
+// LoadOrStore already holds the lock from calling the reader until the value
+// is stored, PessimisticCache makes SetDefault and Delete take it as well,
+// so they cannot change the key while its reader runs.
+type PessimisticCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithPessimisticConcurrency[K comparable, T any](opts ...Option[K, T]) (*PessimisticCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &PessimisticCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *PessimisticCache[K, T]) SetDefault(key K, value T) bool {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+	return c.ristrettoCache.SetDefault(key, value)
+}
+
+func (c *PessimisticCache[K, T]) Delete(key K) {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+	c.ristrettoCache.Delete(key)
+	c.cache.Wait()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// benchmarkSharedMisses loads keys shared by about 8 consecutive operations
+// from parallel goroutines, every key misses once with a 10µs reader.
+func benchmarkSharedMisses(b *testing.B, load func(key int, read reader[int]) int) {
+	read := func() int {
+		time.Sleep(10 * time.Microsecond)
+		return 1
+	}
+	var next atomic.Int64
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			load(int(next.Add(1)/8), read)
+		}
+	})
+}
+
+func BenchmarkPessimisticConcurrency(b *testing.B) {
+	b.Run("lockInItem", func(b *testing.B) {
+		c := newLockInItemCache[int]()
+		defer c.cache.Close()
+		benchmarkSharedMisses(b, c.LoadOrStore)
+	})
+	b.Run("pessimistic", func(b *testing.B) {
+		c, err := NewCacheWithPessimisticConcurrency[int, int]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkSharedMisses(b, c.LoadOrStore)
+	})
+}
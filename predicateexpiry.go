@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// WithExpirePredicate sets the predicate PredicateExpiryCache deletes the
+// entries it returns true for.
+func WithExpirePredicate[K comparable, T any](fn func(key K, value T) bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.expireIf = fn
+	}
+}
+
+// WithExpiryScanInterval sets how often PredicateExpiryCache scans the
+// cache, every minute by default.
+func WithExpiryScanInterval[K comparable, T any](interval time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.expiryScan = interval
+	}
+}
+
+// WithExpiryScanRate limits the number of entries PredicateExpiryCache
+// checks per second, 1000 by default.
+func WithExpiryScanRate[K comparable, T any](entriesPerSec float64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.expiryScanRate = entriesPerSec
+	}
+}
+
+// PredicateExpiryCache deletes entries based on their content, e.g. cached
+// accounts which were deactivated. A background scan checks every entry with
+// the predicate every scan interval, at most at the scan rate, so large
+// caches are not scanned at once.
+type PredicateExpiryCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+	cancel  context.CancelFunc
+}
+
+func NewCacheWithPredicateExpiry[K comparable, T any](expireIf func(K, T) bool, opts ...Option[K, T]) (*PredicateExpiryCache[K, T], error) {
+	c := &PredicateExpiryCache[K, T]{}
+	cache, err := NewCache(append(append([]Option[K, T]{
+		WithExpiryScanInterval[K, T](time.Minute),
+		WithExpiryScanRate[K, T](1000),
+	}, opts...), WithExpirePredicate(expireIf), func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go c.scanLoop(ctx)
+	return c, nil
+}
+
+// ForEach calls fn for the cached values until fn returns false.
+func (c *PredicateExpiryCache[K, T]) ForEach(fn func(key K, value T) bool) {
+	c.entries.Range(func(key, e any) bool {
+		if e.(*entry[K, T]).expired(c.staleTTL) {
+			return true
+		}
+		return fn(key.(K), e.(*entry[K, T]).value)
+	})
+}
+
+// Close stops the scan and closes the cache.
+func (c *PredicateExpiryCache[K, T]) Close() {
+	c.cancel()
+	c.ristrettoCache.Close()
+}
+
+func (c *PredicateExpiryCache[K, T]) scanLoop(ctx context.Context) {
+	limiter := rate.NewLimiter(rate.Limit(c.expiryScanRate), 1)
+	ticker := time.NewTicker(c.expiryScan)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.scan(ctx, limiter)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *PredicateExpiryCache[K, T]) scan(ctx context.Context, limiter *rate.Limiter) {
+	var expired []*entry[K, T]
+	c.entries.Range(func(_, e any) bool {
+		if limiter.Wait(ctx) != nil {
+			return false
+		}
+		if e := e.(*entry[K, T]); c.expireIf(e.key, e.value) {
+			expired = append(expired, e)
+		}
+		return true
+	})
+	for _, e := range expired {
+		c.expire(e)
+	}
+}
+
+// expire deletes the entry unless its key was stored again since it was
+// checked.
+func (c *PredicateExpiryCache[K, T]) expire(e *entry[K, T]) {
+	lock := c.keyLock(e.key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if current, ok := c.entries.Load(e.key); ok && current == e {
+		c.Delete(e.key)
+	}
+}
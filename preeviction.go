@@ -0,0 +1,16 @@
+package main
+
+// WithPreEvictionHook calls fn for entries ristretto evicts. If fn returns
+// true for keepEntry, the entry is stored again with newCost and its
+// remaining TTL, expired entries are never kept. Keeping entries adds writes
+// to a cache already under cost pressure, the new cost should be low enough
+// for the entry to stay.
+func WithPreEvictionHook[K comparable, T any](fn func(key K, value T) (newCost int64, keepEntry bool)) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.preEviction = fn
+	}
+}
+
+func NewCacheWithSmartPreemption[K comparable, T any](fn func(key K, value T) (newCost int64, keepEntry bool), opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithPreEvictionHook(fn))...)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestPreEvictionHookKeepsEntries(t *testing.T) {
+	var kept sync.Map
+	c, err := NewCache(WithMaxCost[string, int](10), WithPreEvictionHook(func(key string, _ int) (int64, bool) {
+		// keep every entry once
+		_, again := kept.LoadOrStore(key, true)
+		return 1, !again
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close evicts the entries without calling the hook
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		c.SetDefault(strconv.Itoa(i), i)
+	}
+	n := 0
+	kept.Range(func(any, any) bool {
+		n++
+		return true
+	})
+	if n == 0 {
+		t.Error("hook not called for evicted entries")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Priority int
+
+const (
+	Low Priority = iota
+	Normal
+	High
+)
+
+// PriorityCache reserves reservedCost of the max cost for high priority
+// entries. They are stored with cost 0, so ristretto always admits them, and
+// they are stored again whenever ristretto evicts them, until they expire or
+// are deleted. High priority entries not fitting into the reserved cost are
+// stored like normal ones. Entries of other priorities share the rest of the
+// max cost.
+type PriorityCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	reservedCost int64
+
+	// pending holds the priority of misses in progress, the misses hold the
+	// key lock
+	pending sync.Map
+
+	mu   sync.Mutex
+	high map[K]int64
+	// highCost is the cost of the high priority entries
+	highCost int64
+}
+
+var _ Cache[string, int] = (*PriorityCache[string, int])(nil)
+
+func NewCacheWithPriority[K comparable, T any](reservedCost int64, opts ...Option[K, T]) (*PriorityCache[K, T], error) {
+	o := options[K, T]{maxCost: 1 << 30}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if reservedCost < 0 || reservedCost >= o.maxCost {
+		return nil, fmt.Errorf("reserved cost %d must be between 0 and the max cost %d", reservedCost, o.maxCost)
+	}
+
+	c := &PriorityCache[K, T]{
+		reservedCost: reservedCost,
+		high:         map[K]int64{},
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.maxCost -= reservedCost
+		o.storeMiss = c.storeMiss
+		preEviction := o.preEviction
+		o.preEviction = func(key K, value T) (int64, bool) {
+			if c.isHigh(key) {
+				return 0, true
+			}
+			if preEviction != nil {
+				return preEviction(key, value)
+			}
+			return 0, false
+		}
+		onEvicted := o.onEvicted
+		o.onEvicted = func(e *entry[K, T]) {
+			// only expired high priority entries are evicted
+			c.release(e.key)
+			if onEvicted != nil {
+				onEvicted(e)
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *PriorityCache[K, T]) LoadOrStoreWithPriority(key K, priority Priority, read reader[T]) T {
+	if priority == High {
+		p := &priority
+		c.pending.Store(key, p)
+		defer c.pending.CompareAndDelete(key, p)
+	}
+	return c.LoadOrStore(key, read)
+}
+
+func (c *PriorityCache[K, T]) SetDefault(key K, value T) bool {
+	c.release(key)
+	return c.ristrettoCache.SetDefault(key, value)
+}
+
+func (c *PriorityCache[K, T]) Delete(key K) {
+	c.release(key)
+	c.ristrettoCache.Delete(key)
+}
+
+func (c *PriorityCache[K, T]) storeMiss(e *entry[K, T]) error {
+	if _, ok := c.pending.Load(e.key); !ok || !c.reserve(e.key, e.cost) {
+		c.release(e.key)
+	} else {
+		e.cost = 0
+	}
+	if c.setEntry(e) {
+		c.cache.Wait()
+	} else if e.cost == 0 {
+		c.release(e.key)
+	}
+	return nil
+}
+
+// reserve counts cost against the reserved cost, replacing the cost of a
+// high priority entry stored under the key before. It reports false if the
+// cost does not fit.
+func (c *PriorityCache[K, T]) reserve(key K, cost int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.highCost-c.high[key]+cost > c.reservedCost {
+		return false
+	}
+	c.highCost += cost - c.high[key]
+	c.high[key] = cost
+	return true
+}
+
+func (c *PriorityCache[K, T]) release(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cost, ok := c.high[key]; ok {
+		c.highCost -= cost
+		delete(c.high, key)
+	}
+}
+
+func (c *PriorityCache[K, T]) isHigh(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.high[key]
+	return ok
+}
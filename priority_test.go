@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHighPriorityEntriesAreNotEvicted(t *testing.T) {
+	c, err := NewCacheWithPriority(3,
+		WithMaxCost[string, int](10),
+		WithSizer[string](SizerFunc[int](func(int) int64 { return 1 })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		c.LoadOrStoreWithPriority("high:"+strconv.Itoa(i), High, func() int { return i })
+	}
+	for i := 0; i < 1000; i++ {
+		c.LoadOrStoreWithPriority("low:"+strconv.Itoa(i), Low, func() int { return i })
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := c.Peek("high:" + strconv.Itoa(i)); !ok {
+			t.Errorf("high:%d evicted", i)
+		}
+	}
+
+	// the reserved cost is used up
+	c.LoadOrStoreWithPriority("high:3", High, func() int { return 3 })
+	if c.isHigh("high:3") {
+		t.Error("high:3 stored beyond the reserved cost")
+	}
+	c.Delete("high:0")
+	c.LoadOrStoreWithPriority("high:4", High, func() int { return 4 })
+	if !c.isHigh("high:4") {
+		t.Error("cost of the deleted high:0 still reserved")
+	}
+}
+
+func TestPriorityReservedCostMustBeBelowMaxCost(t *testing.T) {
+	if _, err := NewCacheWithPriority(10, WithMaxCost[string, int](10)); err == nil {
+		t.Error("reserved cost of the whole cache accepted")
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "math/rand"
+
+// ProbabilisticAdmissionCache stores read values only with probability
+// admitRate, so keys read once rarely make it into the cache while keys read
+// often are stored after a few misses. This takes write pressure off
+// ristretto under random key workloads, its TinyLFU admission still decides
+// which of the admitted values are kept.
+type ProbabilisticAdmissionCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithProbabilisticAdmission[K comparable, T any](admitRate float64, opts ...Option[K, T]) (*ProbabilisticAdmissionCache[K, T], error) {
+	cache, err := NewCache(append(opts, WithAllowIf(func(K, T) bool {
+		return admitRate >= 1 || rand.Float64() < admitRate
+	}))...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProbabilisticAdmissionCache[K, T]{ristrettoCache: cache}, nil
+}
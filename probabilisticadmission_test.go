@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestProbabilisticAdmissionStoresAFractionOfMisses(t *testing.T) {
+	c, err := NewCacheWithProbabilisticAdmission[string, int](0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	stored := 0
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		c.LoadOrStore(key, func() int { return i })
+		if _, ok := c.Peek(key); ok {
+			stored++
+		}
+	}
+	if stored < 50 || stored > 150 {
+		t.Errorf("%d of 1000 misses stored with an admit rate of 0.1", stored)
+	}
+}
+
+func TestProbabilisticAdmissionAppliesKeySchema(t *testing.T) {
+	c, err := NewCacheWithProbabilisticAdmission(1, WithKeySchema[string, int](regexp.MustCompile(`^valid$`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.LoadOrStoreE("invalid", func() (int, error) { return 1, nil }); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("got %v, want %v", err, ErrInvalidKey)
+	}
+	if _, err := c.LoadOrStoreE("valid", func() (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Peek("valid"); !ok {
+		t.Error("admitted value not cached")
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "google.golang.org/protobuf/proto"
+
+// ProtoCached caches protocol buffer messages in their wire format in a
+// CompressionCache, values returned from the cache are new messages which
+// can be modified freely. Unknown fields are kept.
+type ProtoCached[K comparable, T proto.Message] struct {
+	*CompressionCache[K, T]
+}
+
+var _ Cache[string, proto.Message] = (*ProtoCached[string, proto.Message])(nil)
+
+// NewCacheWithTypeRegistry returns a cache of the messages created by
+// newMessage, e.g. func() *pb.User { return &pb.User{} }. T may be an
+// interface type like proto.Message as long as newMessage returns messages
+// of a concrete type. Messages marshaled to more than minSize bytes are
+// stored compressed.
+func NewCacheWithTypeRegistry[K comparable, T proto.Message](minSize int, newMessage func() T, opts ...Option[K, []byte]) (*ProtoCached[K, T], error) {
+	marshal := func(b []byte, value T) ([]byte, error) {
+		return proto.MarshalOptions{}.MarshalAppend(b, value)
+	}
+	unmarshal := func(data []byte) (T, error) {
+		value := newMessage()
+		if err := proto.Unmarshal(data, value); err != nil {
+			var zero T
+			return zero, err
+		}
+		return value, nil
+	}
+	cache, err := newCompressionCache(minSize, marshal, unmarshal, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtoCached[K, T]{CompressionCache: cache}, nil
+}
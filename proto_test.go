@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jakubtomany/cachetest/internal/cachepb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestProtoCachedRoundTrip(t *testing.T) {
+	c, err := NewCacheWithTypeRegistry[string](1024, func() *cachepb.CacheEntry {
+		return &cachepb.CacheEntry{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, entry := range []*cachepb.CacheEntry{
+		{Key: "number", Payload: &cachepb.CacheEntry_Number{Number: 42}},
+		// stored compressed
+		{Key: "text", Payload: &cachepb.CacheEntry_Text{Text: strings.Repeat("text ", 1000)}},
+	} {
+		// field 9 is unknown to CacheEntry and must survive the round trip
+		entry.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 9, protowire.VarintType), 7))
+
+		loaded, err := c.LoadOrStoreE(entry.Key, func() (*cachepb.CacheEntry, error) { return entry, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		cached, ok := c.Peek(entry.Key)
+		if !ok {
+			t.Fatalf("%s not cached", entry.Key)
+		}
+		for _, got := range []*cachepb.CacheEntry{loaded, cached} {
+			if !proto.Equal(got, entry) {
+				t.Errorf("got %v, want %v", got, entry)
+			}
+			if len(got.ProtoReflect().GetUnknown()) == 0 {
+				t.Error("unknown field dropped")
+			}
+		}
+		if cached == entry || cached == loaded {
+			t.Error("cached message shared with the caller")
+		}
+	}
+	if e, ok := c.cache.get("text"); !ok || e.value[0] != compressedValue {
+		t.Error("large message not stored compressed")
+	}
+}
+
+func TestProtoCachedInterfaceType(t *testing.T) {
+	c, err := NewCacheWithTypeRegistry[string](1024, func() proto.Message { return &structpb.Value{} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.LoadOrStore("k", func() proto.Message { return structpb.NewStringValue("v") })
+	cached, ok := c.Peek("k")
+	if !ok {
+		t.Fatal("message not cached")
+	}
+	if value := cached.(*structpb.Value).GetStringValue(); value != "v" {
+		t.Errorf("got %q, want %q", value, "v")
+	}
+}
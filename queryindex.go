@@ -0,0 +1,110 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// QueryableCache keeps inverted indexes of fields of the cached values, so
+// entries can be looked up by other fields than their key. The indexes
+// follow stores and removals of entries.
+type QueryableCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu sync.RWMutex
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries map[K]*entry[K, T]
+	indexes map[string]*fieldIndex[K, T]
+}
+
+// fieldIndex holds the stored entries by the value of their field.
+type fieldIndex[K comparable, T any] struct {
+	extractor func(value T) any
+	entries   map[any]map[*entry[K, T]]struct{}
+}
+
+func (i *fieldIndex[K, T]) add(e *entry[K, T]) {
+	field := i.extractor(e.value)
+	if i.entries[field] == nil {
+		i.entries[field] = make(map[*entry[K, T]]struct{})
+	}
+	i.entries[field][e] = struct{}{}
+}
+
+func (i *fieldIndex[K, T]) remove(e *entry[K, T]) {
+	field := i.extractor(e.value)
+	delete(i.entries[field], e)
+	if len(i.entries[field]) == 0 {
+		delete(i.entries, field)
+	}
+}
+
+func NewCacheWithQueryIndex[K comparable, T any](opts ...Option[K, T]) (*QueryableCache[K, T], error) {
+	c := &QueryableCache[K, T]{
+		entries: make(map[K]*entry[K, T]),
+		indexes: make(map[string]*fieldIndex[K, T]),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.entries[e.key] = e
+			for _, index := range c.indexes {
+				index.add(e)
+			}
+		}
+		o.onExit = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			// the key may have been stored again in the meantime
+			if c.entries[e.key] == e {
+				delete(c.entries, e.key)
+			}
+			for _, index := range c.indexes {
+				index.remove(e)
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// IndexField registers an index of the field returned by extractor, the
+// cached entries are indexed right away. Fields must be comparable.
+// Registering a name again replaces its index.
+func (c *QueryableCache[K, T]) IndexField(name string, extractor func(value T) any) {
+	index := &fieldIndex[K, T]{
+		extractor: extractor,
+		entries:   make(map[any]map[*entry[K, T]]struct{}),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.entries {
+		index.add(e)
+	}
+	c.indexes[name] = index
+}
+
+// Query returns the cached values whose indexed field equals value, in no
+// particular order. It returns nil for unknown indexes.
+func (c *QueryableCache[K, T]) Query(indexName string, value any) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	index, ok := c.indexes[indexName]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	var values []T
+	for e := range index.entries[value] {
+		if e.ttl <= 0 || now.Before(e.expiresAt) {
+			values = append(values, e.value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,22 @@
+package main
+
+import "golang.org/x/sync/semaphore"
+
+// WithReaderSemaphore acquires one unit of sem for every reader call, caches
+// sharing the semaphore share its limit of concurrent reads, e.g. of the
+// same database.
+func WithReaderSemaphore[K comparable, T any](sem *semaphore.Weighted) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.readers = sem
+	}
+}
+
+func NewCacheWithBoundedParallelReaders[K comparable, T any](sem *semaphore.Weighted, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithReaderSemaphore[K, T](sem))...)
+}
+
+// WithMaxConcurrentReads allows at most n reader calls of the cache at a
+// time.
+func WithMaxConcurrentReads[K comparable, T any](n int) Option[K, T] {
+	return WithReaderSemaphore[K, T](semaphore.NewWeighted(int64(n)))
+}
@@ -0,0 +1,72 @@
+package main
+
+import "context"
+
+// ReadWriter is the backing store of CombinedRWCache.
+type ReadWriter[K comparable, T any] interface {
+	Read(ctx context.Context, key K) (T, error)
+	Write(ctx context.Context, key K, value T) error
+}
+
+// CombinedRWCache reads missed keys from the backing store and writes every
+// value it stores through to it, values whose write fails are not cached.
+type CombinedRWCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	rw ReadWriter[K, T]
+}
+
+func NewCacheWithCombinedReadWriter[K comparable, T any](rw ReadWriter[K, T], opts ...Option[K, T]) (*CombinedRWCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CombinedRWCache[K, T]{ristrettoCache: cache, rw: rw}, nil
+}
+
+// Load returns the cached value, reading it from the backing store on a miss.
+func (c *CombinedRWCache[K, T]) Load(ctx context.Context, key K) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, err := c.rw.Read(ctx, key)
+		if err != nil {
+			return value, err
+		}
+		return value, c.rw.Write(ctx, key, value)
+	}, func() (T, error) {
+		return c.rw.Read(ctx, key)
+	})
+	return value, err
+}
+
+func (c *CombinedRWCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE writes the value read on a miss to the backing store.
+func (c *CombinedRWCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, err := read()
+		if err != nil {
+			return value, err
+		}
+		return value, c.rw.Write(context.Background(), key, value)
+	}, read)
+	return value, err
+}
+
+// Store writes the value to the backing store and caches it.
+func (c *CombinedRWCache[K, T]) Store(ctx context.Context, key K, value T) error {
+	if err := c.rw.Write(ctx, key, value); err != nil {
+		return err
+	}
+	c.ristrettoCache.SetDefault(key, value)
+	return nil
+}
+
+// SetDefault reports false if the write to the backing store fails.
+func (c *CombinedRWCache[K, T]) SetDefault(key K, value T) bool {
+	if err := c.rw.Write(context.Background(), key, value); err != nil {
+		return false
+	}
+	return c.ristrettoCache.SetDefault(key, value)
+}
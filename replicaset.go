@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// replicaTimeout is how long ReplicaSetCache waits for a replica before
+// treating it as failed.
+const replicaTimeout = time.Second
+
+// ReplicaSetCache spreads reads over several replicas of a cache. Hits are
+// served from a random replica, misses are read through the key's primary
+// replica, picked by the hash of the key, and the value is copied to all
+// other replicas. A replica not answering within a second is skipped and the
+// next one is tried, the operation keeps running in the background. If no
+// replica answers, the reader is called directly.
+type ReplicaSetCache[K comparable, T any] struct {
+	replicas []Cache[K, T]
+	errors   atomic.Uint64
+}
+
+func NewCacheWithReplicaSet[K comparable, T any](replicas []Cache[K, T]) (*ReplicaSetCache[K, T], error) {
+	if len(replicas) == 0 {
+		return nil, errors.New("replica set needs at least one replica")
+	}
+	return &ReplicaSetCache[K, T]{replicas: replicas}, nil
+}
+
+func (c *ReplicaSetCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	hash, _ := keyToHash(key)
+	primary := int(hash % uint64(len(c.replicas)))
+	for i := range c.replicas {
+		index := (primary + i) % len(c.replicas)
+		var value T
+		if !withReplicaTimeout(func() { value = c.replicas[index].LoadOrStore(key, read) }) {
+			continue
+		}
+		c.copy(key, value, index)
+		return value
+	}
+	return read()
+}
+
+// Peek looks the key up in a random replica.
+func (c *ReplicaSetCache[K, T]) Peek(key K) (T, bool) {
+	var value T
+	var ok bool
+	replica := c.replicas[rand.Intn(len(c.replicas))]
+	if !withReplicaTimeout(func() { value, ok = replica.Peek(key) }) {
+		var zero T
+		return zero, false
+	}
+	return value, ok
+}
+
+// SetDefault stores the value in all replicas and reports whether all of
+// them accepted it.
+func (c *ReplicaSetCache[K, T]) SetDefault(key K, value T) bool {
+	accepted := true
+	for _, replica := range c.replicas {
+		if !replica.SetDefault(key, value) {
+			accepted = false
+		}
+	}
+	return accepted
+}
+
+// Delete deletes the key from all replicas.
+func (c *ReplicaSetCache[K, T]) Delete(key K) {
+	for _, replica := range c.replicas {
+		replica.Delete(key)
+	}
+}
+
+// Stats counts replicas which did not accept a copied value as fanout
+// errors.
+func (c *ReplicaSetCache[K, T]) Stats() Stats {
+	return Stats{FanoutErrors: c.errors.Load()}
+}
+
+// copy stores the value read through the replica at index in all other
+// replicas.
+func (c *ReplicaSetCache[K, T]) copy(key K, value T, index int) {
+	for i, replica := range c.replicas {
+		if i == index {
+			continue
+		}
+		go func(replica Cache[K, T]) {
+			if !replica.SetDefault(key, value) {
+				c.errors.Add(1)
+			}
+		}(replica)
+	}
+}
+
+// withReplicaTimeout runs op and reports whether it returned in time, op
+// must only write variables the caller reads when it returns true.
+func withReplicaTimeout(op func()) bool {
+	timer := time.NewTimer(replicaTimeout)
+	defer timer.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		op()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
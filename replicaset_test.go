@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestReplicaSetRequiresReplicas(t *testing.T) {
+	if _, err := NewCacheWithReplicaSet[string, int](nil); err == nil {
+		t.Error("empty replica set accepted")
+	}
+}
+
+func TestReplicaSetCopiesMisses(t *testing.T) {
+	replicas := make([]Cache[string, int], 3)
+	for i := range replicas {
+		c, err := NewCache[string, int]()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		replicas[i] = c
+	}
+	c, err := NewCacheWithReplicaSet(replicas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value := c.LoadOrStore("k", func() int { return 1 }); value != 1 {
+		t.Fatalf("got %d, want 1", value)
+	}
+	for i := 0; i < 10; i++ {
+		if value := c.LoadOrStore("k", func() int { return 2 }); value != 1 {
+			t.Errorf("got %d from a replica, want the copied 1", value)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "sync"
+
+// RequestCache remembers the values loaded during a request in addition to
+// caching them globally, so all code paths of a request see the same value
+// of a key even if the global entry changes meanwhile. CloseRequest must be
+// called when the request is done to release its values.
+type RequestCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	requests sync.Map
+}
+
+type requestValues[K comparable, T any] struct {
+	mu     sync.Mutex
+	values map[K]T
+}
+
+func NewCacheWithCrossRequestDeduplication[K comparable, T any](opts ...Option[K, T]) (*RequestCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestCache[K, T]{ristrettoCache: cache}, nil
+}
+
+// LoadOrStoreForRequest returns the value loaded for the key earlier in the
+// request, otherwise it loads the value from the global cache.
+func (c *RequestCache[K, T]) LoadOrStoreForRequest(requestID string, key K, read reader[T]) T {
+	anyValues, _ := c.requests.LoadOrStore(requestID, &requestValues[K, T]{values: make(map[K]T)})
+	values := anyValues.(*requestValues[K, T])
+
+	values.mu.Lock()
+	value, ok := values.values[key]
+	values.mu.Unlock()
+	if ok {
+		return value
+	}
+
+	value = c.LoadOrStore(key, read)
+
+	values.mu.Lock()
+	defer values.mu.Unlock()
+	// another code path of the request may have loaded the key meanwhile
+	if loaded, ok := values.values[key]; ok {
+		return loaded
+	}
+	values.values[key] = value
+	return value
+}
+
+// CloseRequest releases the values loaded during the request.
+func (c *RequestCache[K, T]) CloseRequest(requestID string) {
+	c.requests.Delete(requestID)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// WithMinRetention keeps the n most recently used entries even if ristretto
+// evicts them, until they expire.
+func WithMinRetention[K comparable, T any](n int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.minRetention = n
+	}
+}
+
+// WithMaxItems does not store new values while the cache holds m entries.
+func WithMaxItems[K comparable, T any](m int) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.maxItems = m
+	}
+}
+
+// RetentionPolicyCache enforces the WithMinRetention and WithMaxItems
+// options.
+type RetentionPolicyCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	items atomic.Int64
+
+	mu       sync.Mutex
+	recent   *list.List
+	elements map[K]*list.Element
+}
+
+func NewCacheWithRetentionPolicy[K comparable, T any](opts ...Option[K, T]) (*RetentionPolicyCache[K, T], error) {
+	var o options[K, T]
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxItems > 0 && o.minRetention > o.maxItems {
+		return nil, fmt.Errorf("min retention %d exceeds max items %d", o.minRetention, o.maxItems)
+	}
+
+	c := &RetentionPolicyCache[K, T]{
+		recent:   list.New(),
+		elements: map[K]*list.Element{},
+	}
+	opts = append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.items.Add(1)
+			c.touch(e)
+		}
+		o.onExit = func(*entry[K, T]) {
+			c.items.Add(-1)
+		}
+	})
+	if o.maxItems > 0 {
+		opts = append(opts, WithAllowIf(func(K, T) bool {
+			return c.items.Load() < int64(o.maxItems)
+		}))
+	}
+
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+func (c *RetentionPolicyCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *RetentionPolicyCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if e, ok := c.get(key); ok {
+		c.touch(e)
+		return e.value, nil
+	}
+	if e, ok := c.retained(key); ok {
+		// put the entry evicted by ristretto back, it still expires when
+		// it would have before
+		if c.storeWithCost(e, e.cost) {
+			c.cache.Wait()
+		}
+		return e.value, nil
+	}
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *RetentionPolicyCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	if element, ok := c.elements[key]; ok {
+		c.recent.Remove(element)
+		delete(c.elements, key)
+	}
+	c.mu.Unlock()
+
+	c.ristrettoCache.Delete(key)
+}
+
+// touch marks the entry as the most recently used one.
+func (c *RetentionPolicyCache[K, T]) touch(e *entry[K, T]) {
+	if c.minRetention == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.elements[e.key]; ok {
+		element.Value = e
+		c.recent.MoveToFront(element)
+		return
+	}
+	c.elements[e.key] = c.recent.PushFront(e)
+	if c.recent.Len() > c.minRetention {
+		oldest := c.recent.Remove(c.recent.Back()).(*entry[K, T])
+		delete(c.elements, oldest.key)
+	}
+}
+
+func (c *RetentionPolicyCache[K, T]) retained(key K) (*entry[K, T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.elements[key]
+	if !ok {
+		return nil, false
+	}
+	e := element.Value.(*entry[K, T])
+	if e.expired(0) {
+		return nil, false
+	}
+	return e, true
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetainedEntriesKeepTheirExpiration(t *testing.T) {
+	c, err := NewCacheWithRetentionPolicy(WithMinRetention[string, int](1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.LoadOrStore("k", func() int { return 1 })
+	stored, ok := c.get("k")
+	if !ok {
+		t.Fatal("entry not stored")
+	}
+	// evict the entry behind the back of the retention policy
+	c.cache.Del("k")
+	c.cache.Wait()
+	time.Sleep(10 * time.Millisecond)
+
+	value := c.LoadOrStore("k", func() int {
+		t.Error("retained entry read again")
+		return 2
+	})
+	if value != 1 {
+		t.Errorf("got %d, want the retained 1", value)
+	}
+	ttl, ok := c.cache.GetTTL("k")
+	if !ok {
+		t.Fatal("retained entry not stored again")
+	}
+	// allow for ristretto computing the expiration on its own
+	if remaining := time.Until(stored.expiresAt); ttl > remaining+time.Millisecond {
+		t.Errorf("retained entry stored with TTL %v, want at most the remaining %v", ttl, remaining)
+	}
+}
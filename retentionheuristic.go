@@ -0,0 +1,51 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EntryStats describes the accesses of a cached entry.
+type EntryStats struct {
+	HitCount     int
+	InsertedAt   time.Time
+	LastAccessed time.Time
+	Cost         int64
+}
+
+// WithRetentionHeuristic calls fn for entries ristretto evicts. Entries for
+// which fn returns true are stored again with twice their cost and their
+// remaining TTL, expired entries are never kept.
+func WithRetentionHeuristic[K comparable, T any](fn func(key K, value T, stats EntryStats) bool) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.retention = fn
+	}
+}
+
+func NewCacheWithRetentionHeuristic[K comparable, T any](heuristic func(key K, value T, stats EntryStats) bool, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithRetentionHeuristic(heuristic))...)
+}
+
+type entryAccess struct {
+	hits       atomic.Int64
+	lastAccess atomic.Int64
+}
+
+// hit records an access and returns the number of hits so far.
+func (a *entryAccess) hit() int64 {
+	a.lastAccess.Store(time.Now().UnixNano())
+	return a.hits.Add(1)
+}
+
+func (a *entryAccess) stats(insertedAt time.Time, cost int64) EntryStats {
+	stats := EntryStats{
+		HitCount:     int(a.hits.Load()),
+		InsertedAt:   insertedAt,
+		LastAccessed: insertedAt,
+		Cost:         cost,
+	}
+	if last := a.lastAccess.Load(); last != 0 {
+		stats.LastAccessed = time.Unix(0, last)
+	}
+	return stats
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrNoShards = errors.New("no shards left")
+
+// RingHashCache spreads keys over one cache per node with consistent
+// hashing, so adding or removing a node only moves the keys of about one
+// node's share. Moved keys are read again on their new shard, the entries
+// of removed nodes are dropped.
+type RingHashCache[K ~string, T any] struct {
+	opts []Option[K, T]
+
+	mu     sync.RWMutex
+	ring   *hashRing
+	shards map[string]*ristrettoCache[K, T]
+}
+
+// NewCacheWithRingHashSharding returns ErrNoShards if nodes is empty.
+func NewCacheWithRingHashSharding[K ~string, T any](nodes []string, opts ...Option[K, T]) (*RingHashCache[K, T], error) {
+	if len(nodes) == 0 {
+		return nil, ErrNoShards
+	}
+	c := &RingHashCache[K, T]{
+		opts:   opts,
+		ring:   newHashRing(nil),
+		shards: make(map[string]*ristrettoCache[K, T], len(nodes)),
+	}
+	for _, node := range nodes {
+		if err := c.AddNode(node); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *RingHashCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.shard(key).LoadOrStore(key, read)
+}
+
+func (c *RingHashCache[K, T]) Peek(key K) (T, bool) {
+	return c.shard(key).Peek(key)
+}
+
+func (c *RingHashCache[K, T]) SetDefault(key K, value T) bool {
+	return c.shard(key).SetDefault(key, value)
+}
+
+func (c *RingHashCache[K, T]) Delete(key K) {
+	c.shard(key).Delete(key)
+}
+
+// Node returns the node owning the key.
+func (c *RingHashCache[K, T]) Node(key K) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ring.node(key)
+}
+
+// AddNode adds a shard for the node, nodes already on the ring are ignored.
+func (c *RingHashCache[K, T]) AddNode(node string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.shards[node]; ok {
+		return nil
+	}
+	shard, err := NewCache(c.opts...)
+	if err != nil {
+		return err
+	}
+	c.shards[node] = shard
+	c.ring.add(node)
+	return nil
+}
+
+// RemoveNode closes the shard of the node, the last node cannot be removed.
+func (c *RingHashCache[K, T]) RemoveNode(node string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	shard, ok := c.shards[node]
+	if !ok {
+		return nil
+	}
+	if len(c.shards) == 1 {
+		return ErrNoShards
+	}
+	delete(c.shards, node)
+	c.ring.remove(node)
+	shard.Close()
+	return nil
+}
+
+func (c *RingHashCache[K, T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, shard := range c.shards {
+		shard.Close()
+	}
+}
+
+// shard returns the cache of the node owning the key, the ring must not be
+// empty.
+func (c *RingHashCache[K, T]) shard(key K) *ristrettoCache[K, T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.shards[c.ring.node(key)]
+}
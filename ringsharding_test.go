@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestRingHashShardingRequiresNodes(t *testing.T) {
+	if _, err := NewCacheWithRingHashSharding[string, int](nil); !errors.Is(err, ErrNoShards) {
+		t.Errorf("got %v, want %v", err, ErrNoShards)
+	}
+}
+
+func TestRingHashShardingAddNodeMovesOneShare(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d"}
+	c, err := NewCacheWithRingHashSharding[string, int](nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const keys = 10000
+	before := make([]string, keys)
+	for i := range before {
+		before[i] = c.Node("key:" + strconv.Itoa(i))
+	}
+	if err := c.AddNode("e"); err != nil {
+		t.Fatal(err)
+	}
+	moved := 0
+	for i, node := range before {
+		if after := c.Node("key:" + strconv.Itoa(i)); after != node {
+			if after != "e" {
+				t.Fatalf("key %d moved from %s to %s instead of the new node", i, node, after)
+			}
+			moved++
+		}
+	}
+	// about 1/(N+1) of the keys move to the new node
+	if want := keys / (len(nodes) + 1); moved < want*3/4 || moved > want*5/4 {
+		t.Errorf("%d of %d keys moved, want about %d", moved, keys, want)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// RoaringBitmapCache tracks the cached keys in a roaring bitmap, which is
+// far more compact than a map for large integer key spaces. Keys rejected by
+// ristretto's admission may be reported until the rejection is processed.
+type RoaringBitmapCache[K ~uint32, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu   sync.RWMutex
+	keys *roaring.Bitmap
+}
+
+func NewCacheWithRoaringBitmap[K ~uint32, T any](opts ...Option[K, T]) (*RoaringBitmapCache[K, T], error) {
+	c := &RoaringBitmapCache[K, T]{keys: roaring.New()}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.keys.Add(uint32(e.key))
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// updates call onExit with the replaced entry, keep keys which
+			// are still stored
+			if _, ok := c.stored(e.key); ok {
+				return
+			}
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.keys.Remove(uint32(e.key))
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// Len returns the number of cached keys.
+func (c *RoaringBitmapCache[K, T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return int(c.keys.GetCardinality())
+}
+
+// Keys returns the cached keys in ascending order.
+func (c *RoaringBitmapCache[K, T]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]K, 0, c.keys.GetCardinality())
+	it := c.keys.Iterator()
+	for it.HasNext() {
+		keys = append(keys, K(it.Next()))
+	}
+	return keys
+}
+
+// HasKey reports whether the key is cached, without touching the entry.
+func (c *RoaringBitmapCache[K, T]) HasKey(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.keys.Contains(uint32(key))
+}
+
+// DeleteByRangeBitmap deletes all cached keys from min to max inclusive.
+func (c *RoaringBitmapCache[K, T]) DeleteByRangeBitmap(min, max K) {
+	if min > max {
+		return
+	}
+	inRange := roaring.New()
+	inRange.AddRange(uint64(min), uint64(max)+1)
+
+	c.mu.Lock()
+	inRange.And(c.keys)
+	c.keys.AndNot(inRange)
+	c.mu.Unlock()
+
+	it := inRange.Iterator()
+	for it.HasNext() {
+		c.Delete(K(it.Next()))
+	}
+	c.cache.Wait()
+}
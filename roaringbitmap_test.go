@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestRoaringBitmapTracksKeys(t *testing.T) {
+	c, err := NewCacheWithRoaringBitmap[uint32, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close evicts the cached entries, which must not deadlock
+	defer c.Close()
+
+	for key := uint32(0); key < 10; key++ {
+		c.SetDefault(key, "v")
+	}
+	c.SetDefault(3, "updated")
+	c.Delete(4)
+	c.cache.Wait()
+
+	if n := c.Len(); n != 9 {
+		t.Errorf("Len() = %d, want 9", n)
+	}
+	if !c.HasKey(3) {
+		t.Error("updated key missing")
+	}
+	if c.HasKey(4) {
+		t.Error("deleted key reported")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// SampledStatsCache counts hits and misses of only a sampleRate fraction of
+// LoadOrStore calls, Stats extrapolates the counts to all calls.
+type SampledStatsCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	sampleRate float64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+func NewCacheWithStatisticalSampling[K comparable, T any](sampleRate float64, opts ...Option[K, T]) (*SampledStatsCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SampledStatsCache[K, T]{
+		ristrettoCache: cache,
+		sampleRate:     sampleRate,
+	}, nil
+}
+
+func (c *SampledStatsCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *SampledStatsCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if rand.Float64() >= c.sampleRate {
+		return c.ristrettoCache.LoadOrStoreE(key, read)
+	}
+
+	value, hit, err := c.loadOrStoreE(key, read)
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, err
+}
+
+// Stats returns the sampled hits and misses divided by the sample rate.
+func (c *SampledStatsCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	if c.sampleRate > 0 {
+		stats.Hits = uint64(float64(c.hits.Load()) / c.sampleRate)
+		stats.Misses = uint64(float64(c.misses.Load()) / c.sampleRate)
+	}
+	return stats
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestSampledStatsAccuracy(t *testing.T) {
+	// odd keys are never stored, so half of the calls miss
+	c, err := NewCacheWithStatisticalSampling(0.01, WithAllowIf(func(key, _ int) bool { return key%2 == 0 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// about 7,500 sampled hits and misses each keep the error of the
+	// extrapolation below 5% with a margin of 4 standard deviations
+	const calls = 1_500_000
+	for i := 0; i < calls; i++ {
+		key := i % 1000
+		c.LoadOrStore(key, func() int { return key })
+	}
+	stats := c.Stats()
+	// the first load of each even key misses
+	for _, s := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"hits", float64(stats.Hits), calls/2 - 500},
+		{"misses", float64(stats.Misses), calls/2 + 500},
+	} {
+		if s.got < s.want*0.95 || s.got > s.want*1.05 {
+			t.Errorf("extrapolated %.0f %s, want %.0f within 5%%", s.got, s.name, s.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ScheduledRefreshCache refreshes registered keys on a fixed schedule,
+// regardless of their TTL.
+type ScheduledRefreshCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewCacheWithScheduledRefresh[K comparable, T any](opts ...Option[K, T]) (*ScheduledRefreshCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ScheduledRefreshCache[K, T]{
+		ristrettoCache: cache,
+		stop:           make(chan struct{}),
+	}, nil
+}
+
+// RegisterSchedule reads the key every interval and stores the value in the
+// cache. The first read happens after the first interval.
+func (c *ScheduledRefreshCache[K, T]) RegisterSchedule(key K, interval time.Duration, read reader[T]) {
+	c.schedule(key, 0, interval, read)
+}
+
+// schedule starts refreshing the key every interval after waiting for the
+// offset.
+func (c *ScheduledRefreshCache[K, T]) schedule(key K, offset, interval time.Duration, read reader[T]) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if offset > 0 {
+			timer := time.NewTimer(offset)
+			select {
+			case <-timer.C:
+			case <-c.stop:
+				timer.Stop()
+				return
+			}
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.refresh(key, read)
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops all schedules and closes the cache.
+func (c *ScheduledRefreshCache[K, T]) Close() {
+	close(c.stop)
+	c.wg.Wait()
+	c.ristrettoCache.Close()
+}
+
+func (c *ScheduledRefreshCache[K, T]) refresh(key K, read reader[T]) {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.SetDefault(key, read())
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduledRefreshReadsOncePerInterval(t *testing.T) {
+	c, err := NewCacheWithScheduledRefresh[string, int64]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n, interval = 5, 100 * time.Millisecond
+	var reads atomic.Int64
+	c.RegisterSchedule("k", interval, func() int64 {
+		return reads.Add(1)
+	})
+	// stop half an interval after the nth tick
+	time.Sleep(n*interval + interval/2)
+	c.Close()
+
+	if got := reads.Load(); got != n {
+		t.Errorf("reader called %d times in %d intervals", got, n)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+// versionedValue is a value tagged with the schema version it was stored
+// with, values of older versions hold the old type.
+type versionedValue struct {
+	Version uint8
+	Value   any
+}
+
+// WithSchemaVersion sets the schema version SchemaEvolutionCache stores new
+// values with, 0 by default.
+func WithSchemaVersion[K comparable](version uint8) Option[K, versionedValue] {
+	return func(o *options[K, versionedValue]) {
+		o.schemaVersion = version
+	}
+}
+
+// SchemaEvolutionCache stores the schema version with every value. Values of
+// another version, e.g. restored from a snapshot of an older release, are
+// upgraded with the migrator when they are read and stored again with their
+// remaining TTL.
+type SchemaEvolutionCache[K comparable, T any] struct {
+	cache    *ristrettoCache[K, versionedValue]
+	migrator func(old any) T
+}
+
+func NewCacheWithSchemaEvolution[K comparable, T any](migrator func(old any) T, opts ...Option[K, versionedValue]) (*SchemaEvolutionCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaEvolutionCache[K, T]{
+		cache:    cache,
+		migrator: migrator,
+	}, nil
+}
+
+func (c *SchemaEvolutionCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	v := c.cache.LoadOrStore(key, func() versionedValue {
+		return versionedValue{Version: c.cache.schemaVersion, Value: read()}
+	})
+	if v.Version == c.cache.schemaVersion {
+		return v.Value.(T)
+	}
+	return c.migrate(key, v)
+}
+
+// Get returns the cached value, upgrading it if it was stored with another
+// schema version.
+func (c *SchemaEvolutionCache[K, T]) Get(key K) (T, bool) {
+	e, ok := c.cache.get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	if e.value.Version == c.cache.schemaVersion {
+		return e.value.Value.(T), true
+	}
+	return c.migrate(key, e.value), true
+}
+
+func (c *SchemaEvolutionCache[K, T]) SetDefault(key K, value T) bool {
+	return c.cache.SetDefault(key, versionedValue{Version: c.cache.schemaVersion, Value: value})
+}
+
+// SetVersioned stores a value of the given schema version, it is upgraded
+// on the next read.
+func (c *SchemaEvolutionCache[K, T]) SetVersioned(key K, version uint8, value any) bool {
+	return c.cache.SetDefault(key, versionedValue{Version: version, Value: value})
+}
+
+func (c *SchemaEvolutionCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *SchemaEvolutionCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+// migrate upgrades the old value read for the key holding the key's lock, so
+// a cached value is migrated only once. The old value is migrated without
+// storing it if it is not cached anymore.
+func (c *SchemaEvolutionCache[K, T]) migrate(key K, old versionedValue) T {
+	lock := c.cache.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	e, ok := c.cache.get(key)
+	if !ok {
+		return c.migrator(old.Value)
+	}
+	if e.value.Version == c.cache.schemaVersion {
+		return e.value.Value.(T)
+	}
+
+	value := c.migrator(e.value.Value)
+	upgraded := *e
+	upgraded.value = versionedValue{Version: c.cache.schemaVersion, Value: value}
+	c.cache.storeWithCost(&upgraded, upgraded.cost)
+	c.cache.cache.Wait()
+	return value
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type userV1 struct {
+	Name string
+}
+
+type userV2 struct {
+	First, Last string
+}
+
+func TestSchemaEvolutionUpgradesOldValues(t *testing.T) {
+	var migrations atomic.Int32
+	c, err := NewCacheWithSchemaEvolution(func(old any) userV2 {
+		migrations.Add(1)
+		first, last, _ := strings.Cut(old.(userV1).Name, " ")
+		return userV2{First: first, Last: last}
+	}, WithSchemaVersion[string](2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// a value stored by the previous release
+	c.SetVersioned("u", 1, userV1{Name: "Ada Lovelace"})
+	want := userV2{First: "Ada", Last: "Lovelace"}
+	for i := 0; i < 3; i++ {
+		if got, ok := c.Get("u"); !ok || got != want {
+			t.Errorf("got %+v, %v, want %+v", got, ok, want)
+		}
+	}
+	if got := c.LoadOrStore("u", func() userV2 { return userV2{} }); got != want {
+		t.Errorf("LoadOrStore got %+v, want %+v", got, want)
+	}
+	if n := migrations.Load(); n != 1 {
+		t.Errorf("value migrated %d times, want once and stored upgraded", n)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStorage is a backend of SegregatedStorage, e.g. a ristretto cache,
+// one holding compressed values or a cache on persistent memory.
+type CacheStorage[K comparable, T any] interface {
+	Peek(key K) (T, bool)
+	SetDefault(key K, value T) bool
+	Delete(key K)
+}
+
+// WithHotDuration sets how long SegregatedStorage keeps entries which are
+// not accessed in the hot storage, 1m by default.
+func WithHotDuration[K comparable, T any](d time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.hotDuration = d
+	}
+}
+
+// SegregatedStorage stores read values in the hot storage and demotes them
+// to the cold storage once they have not been accessed for the hot duration.
+// Demotion runs in the background every hot duration. Lookups check the hot
+// storage first, values found in the cold storage are promoted again.
+type SegregatedStorage[K comparable, T any] struct {
+	hot, cold   CacheStorage[K, T]
+	hotDuration time.Duration
+	locks       sync.Map
+	// accessed holds an *atomic.Int64 with the last access in Unix
+	// nanoseconds per key stored in the hot storage
+	accessed sync.Map
+	stop     chan struct{}
+}
+
+func NewCacheWithSegregatedStorage[K comparable, T any](hot, cold CacheStorage[K, T], opts ...Option[K, T]) *SegregatedStorage[K, T] {
+	o := options[K, T]{hotDuration: time.Minute}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &SegregatedStorage[K, T]{
+		hot:         hot,
+		cold:        cold,
+		hotDuration: o.hotDuration,
+		stop:        make(chan struct{}),
+	}
+	go c.demoteLoop()
+	return c
+}
+
+func (c *SegregatedStorage[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if value, ok := c.hot.Peek(key); ok {
+		c.touch(key)
+		return value
+	}
+	value := read()
+	c.storeHot(key, value)
+	return value
+}
+
+func (c *SegregatedStorage[K, T]) Peek(key K) (T, bool) {
+	if value, ok := c.hot.Peek(key); ok {
+		c.touch(key)
+		return value, true
+	}
+
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// the key may have been promoted meanwhile
+	if value, ok := c.hot.Peek(key); ok {
+		c.touch(key)
+		return value, true
+	}
+	value, ok := c.cold.Peek(key)
+	if ok && c.storeHot(key, value) {
+		c.cold.Delete(key)
+	}
+	return value, ok
+}
+
+func (c *SegregatedStorage[K, T]) SetDefault(key K, value T) bool {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.cold.Delete(key)
+	return c.storeHot(key, value)
+}
+
+func (c *SegregatedStorage[K, T]) Delete(key K) {
+	lock := c.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.hot.Delete(key)
+	c.cold.Delete(key)
+	c.accessed.Delete(key)
+}
+
+// Close stops demoting entries, the storages are left open.
+func (c *SegregatedStorage[K, T]) Close() {
+	close(c.stop)
+}
+
+func (c *SegregatedStorage[K, T]) keyLock(key K) *sync.Mutex {
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	return anyLock.(*sync.Mutex)
+}
+
+func (c *SegregatedStorage[K, T]) storeHot(key K, value T) bool {
+	if !c.hot.SetDefault(key, value) {
+		return false
+	}
+	c.touch(key)
+	return true
+}
+
+func (c *SegregatedStorage[K, T]) touch(key K) {
+	now := time.Now().UnixNano()
+	if accessed, ok := c.accessed.Load(key); ok {
+		accessed.(*atomic.Int64).Store(now)
+		return
+	}
+	accessed := &atomic.Int64{}
+	accessed.Store(now)
+	c.accessed.Store(key, accessed)
+}
+
+func (c *SegregatedStorage[K, T]) demoteLoop() {
+	ticker := time.NewTicker(c.hotDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.demote()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// demote moves the entries not accessed for the hot duration to the cold
+// storage.
+func (c *SegregatedStorage[K, T]) demote() {
+	cutoff := time.Now().Add(-c.hotDuration).UnixNano()
+	c.accessed.Range(func(key, accessed any) bool {
+		if accessed.(*atomic.Int64).Load() > cutoff {
+			return true
+		}
+		lock := c.keyLock(key.(K))
+		lock.Lock()
+		defer lock.Unlock()
+
+		// the key may have been accessed while waiting for the lock
+		if accessed.(*atomic.Int64).Load() > cutoff {
+			return true
+		}
+		c.accessed.Delete(key)
+		if value, ok := c.hot.Peek(key.(K)); ok {
+			c.cold.SetDefault(key.(K), value)
+			c.hot.Delete(key.(K))
+		}
+		return true
+	})
+}
@@ -0,0 +1,45 @@
+package main
+
+// SentinelCache caches "not found" results as a designated sentinel value,
+// so a cached absence is told apart from a key which was not loaded yet and
+// the reader is not called again until the sentinel expires. Readers return
+// Sentinel when they find nothing.
+type SentinelCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	sentinel   T
+	sentinelEq func(value T) bool
+}
+
+func NewCacheWithSentinelValue[K comparable, T any](sentinel T, sentinelEq func(value T) bool, opts ...Option[K, T]) (*SentinelCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SentinelCache[K, T]{
+		ristrettoCache: cache,
+		sentinel:       sentinel,
+		sentinelEq:     sentinelEq,
+	}, nil
+}
+
+// Sentinel returns the value readers return for keys they find nothing for.
+func (c *SentinelCache[K, T]) Sentinel() T {
+	return c.sentinel
+}
+
+// LoadOrStore returns the value of the key and false if the key is cached
+// or read as not found.
+func (c *SentinelCache[K, T]) LoadOrStore(key K, read reader[T]) (T, bool) {
+	value := c.ristrettoCache.LoadOrStore(key, read)
+	return value, !c.sentinelEq(value)
+}
+
+// LoadOrStoreE works like LoadOrStore, values whose reader fails are not
+// stored and the error is returned to the caller.
+func (c *SentinelCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, bool, error) {
+	value, err := c.ristrettoCache.LoadOrStoreE(key, read)
+	if err != nil {
+		return value, false, err
+	}
+	return value, !c.sentinelEq(value), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SharedEvictionPolicy splits a total cost budget between caches competing
+// for the same memory, in proportion to the weights they were registered
+// with. Every cache's maximum cost is its share, so a cache exceeding its
+// share evicts its own entries instead of those of other caches. Shares are
+// recomputed when a cache is registered, caches left above a lowered share
+// evict down to it as they store new entries.
+type SharedEvictionPolicy struct {
+	totalCostBudget int64
+
+	mu     sync.Mutex
+	shares map[string]*costShare
+}
+
+type costShare struct {
+	weight float64
+	// update sets the maximum cost of the cache, nil until it is created
+	update func(maxCost int64)
+}
+
+type SharedEvictionPolicyOption func(p *SharedEvictionPolicy)
+
+// WithTotalCostBudget sets the cost budget shared by all registered caches,
+// 1 << 30 by default.
+func WithTotalCostBudget(budget int64) SharedEvictionPolicyOption {
+	return func(p *SharedEvictionPolicy) {
+		p.totalCostBudget = budget
+	}
+}
+
+func NewSharedEvictionPolicy(opts ...SharedEvictionPolicyOption) *SharedEvictionPolicy {
+	p := &SharedEvictionPolicy{
+		totalCostBudget: 1 << 30,
+		shares:          make(map[string]*costShare),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// RegisterCache registers the cache name with its weight, registering a
+// name again updates its weight.
+func (p *SharedEvictionPolicy) RegisterCache(name string, weight float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if share, ok := p.shares[name]; ok {
+		share.weight = weight
+	} else {
+		p.shares[name] = &costShare{weight: weight}
+	}
+	p.rebalance()
+}
+
+// Share returns the cost budget of the registered cache name.
+func (p *SharedEvictionPolicy) Share(name string) (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	share, ok := p.shares[name]
+	if !ok {
+		return 0, false
+	}
+	return p.share(share), true
+}
+
+func (p *SharedEvictionPolicy) share(share *costShare) int64 {
+	var total float64
+	for _, s := range p.shares {
+		total += s.weight
+	}
+	if total <= 0 {
+		return 0
+	}
+	return int64(float64(p.totalCostBudget) * share.weight / total)
+}
+
+func (p *SharedEvictionPolicy) rebalance() {
+	for _, share := range p.shares {
+		if share.update != nil {
+			share.update(p.share(share))
+		}
+	}
+}
+
+// NewCacheWithSharedEvictionPolicy creates the cache registered under name,
+// its maximum cost follows its share of the policy's budget.
+func NewCacheWithSharedEvictionPolicy[K comparable, T any](name string, policy *SharedEvictionPolicy, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+
+	share, ok := policy.shares[name]
+	if !ok {
+		return nil, fmt.Errorf("cache %q is not registered with the eviction policy", name)
+	}
+	cache, err := NewCache(append(opts, WithMaxCost[K, T](policy.share(share)))...)
+	if err != nil {
+		return nil, err
+	}
+	share.update = cache.cache.UpdateMaxCost
+	return cache, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// SieveCache evicts entries with the SIEVE algorithm once it holds capacity
+// entries: new entries are added at the head of a queue and hits only mark
+// entries as visited. To evict, a hand moves from the tail towards the head,
+// clearing the mark of visited entries, and evicts the first unvisited one.
+// Unlike LRUCache, hits do not reorder the queue. Entries expire after the
+// default TTL like in the other caches.
+type SieveCache[K comparable, T any] struct {
+	capacity int
+
+	mu      sync.Mutex
+	queue   *list.List
+	entries map[K]*list.Element
+	// hand is the next entry considered for eviction, nil starts at the tail
+	hand *list.Element
+
+	locks sync.Map
+}
+
+type sieveEntry[K comparable, T any] struct {
+	key     K
+	visited bool
+	itemValue[T]
+}
+
+func NewCacheWithSieveEviction[K comparable, T any](capacity int) *SieveCache[K, T] {
+	return &SieveCache[K, T]{
+		capacity: capacity,
+		queue:    list.New(),
+		entries:  make(map[K]*list.Element),
+	}
+}
+
+func (c *SieveCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock := anyLock.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	value := read()
+	c.SetDefault(key, value)
+	return value
+}
+
+// Peek returns the cached value and marks it as visited.
+func (c *SieveCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	e := elem.Value.(*sieveEntry[K, T])
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(elem)
+		var zero T
+		return zero, false
+	}
+	e.visited = true
+	return e.value, true
+}
+
+// SetDefault stores the value with the default TTL, evicting an entry if the
+// cache is full. Updated entries keep their position and count as visited.
+func (c *SieveCache[K, T]) SetDefault(key K, value T) bool {
+	now := time.Now()
+	e := &sieveEntry[K, T]{key: key, itemValue: itemValue[T]{value: value, computedAt: now, expiresAt: now.Add(ttl)}}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		e.visited = true
+		elem.Value = e
+		return true
+	}
+	for c.queue.Len() >= c.capacity && c.queue.Len() > 0 {
+		c.evict()
+	}
+	c.entries[key] = c.queue.PushFront(e)
+	return true
+}
+
+func (c *SieveCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of cached entries including expired ones which
+// have not been removed yet.
+func (c *SieveCache[K, T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.queue.Len()
+}
+
+func (c *SieveCache[K, T]) evict() {
+	hand := c.hand
+	if hand == nil {
+		hand = c.queue.Back()
+	}
+	for e := hand.Value.(*sieveEntry[K, T]); e.visited; e = hand.Value.(*sieveEntry[K, T]) {
+		e.visited = false
+		if hand = hand.Prev(); hand == nil {
+			hand = c.queue.Back()
+		}
+	}
+	// remove moves the hand on to the next entry
+	c.hand = hand
+	c.remove(hand)
+}
+
+func (c *SieveCache[K, T]) remove(elem *list.Element) {
+	if c.hand == elem {
+		c.hand = elem.Prev()
+	}
+	c.queue.Remove(elem)
+	delete(c.entries, elem.Value.(*sieveEntry[K, T]).key)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSieveEvictsUnvisited(t *testing.T) {
+	c := NewCacheWithSieveEviction[string, int](3)
+	c.SetDefault("a", 1)
+	c.SetDefault("b", 2)
+	c.SetDefault("c", 3)
+	c.Peek("a")
+	c.SetDefault("d", 4)
+
+	// the hand passes the visited a and evicts b
+	if _, ok := c.Peek("b"); ok {
+		t.Error("unvisited entry b kept")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := c.Peek(key); !ok {
+			t.Errorf("entry %s evicted", key)
+		}
+	}
+}
+
+// webTraceKeys returns a synthetic trace of web requests: Zipfian requests
+// for popular objects mixed with one-hit wonders which are requested once.
+func webTraceKeys(n int) []int {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.1, 1, uint64(n-1))
+	keys := make([]int, 1<<16)
+	for i := range keys {
+		if r.Intn(3) == 0 {
+			keys[i] = n + i
+		} else {
+			keys[i] = int(z.Uint64())
+		}
+	}
+	return keys
+}
+
+func BenchmarkSieve(b *testing.B) {
+	const capacity = 1000
+	keys := webTraceKeys(10000)
+	b.Run("sieve", func(b *testing.B) {
+		benchmarkLoads(b, NewCacheWithSieveEviction[int, int](capacity), keys)
+	})
+	b.Run("lru", func(b *testing.B) {
+		benchmarkLoads(b, NewCacheWithAccessOrderEviction[int, int](capacity), keys)
+	})
+	b.Run("ristretto", func(b *testing.B) {
+		c, err := NewCache(WithMaxCost[int, int](capacity))
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		benchmarkLoads(b, c, keys)
+	})
+}
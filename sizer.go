@@ -0,0 +1,138 @@
+package main
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Sizer measures the approximate memory size of values in bytes.
+type Sizer[T any] interface {
+	Size(value T) int64
+}
+
+type SizerFunc[T any] func(value T) int64
+
+func (f SizerFunc[T]) Size(value T) int64 {
+	return f(value)
+}
+
+// ReflectSizer measures values by walking them with reflect, adding the
+// memory of the strings, slices, maps and pointers they refer to. Memory
+// referred to more than once by a value is counted once, the overhead of the
+// map buckets is not counted.
+type ReflectSizer[T any] struct{}
+
+func (ReflectSizer[T]) Size(value T) int64 {
+	v := reflect.ValueOf(&value).Elem()
+	return int64(v.Type().Size()) + referredSize(v, map[uintptr]bool{})
+}
+
+// referredSize returns the size of the memory v refers to, not counting the
+// memory at the addresses in seen.
+func referredSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		return int64(v.Type().Elem().Size()) + referredSize(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return int64(v.Elem().Type().Size()) + referredSize(v.Elem(), seen)
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += referredSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += referredSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += referredSize(v.Field(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Len()) * int64(v.Type().Key().Size()+v.Type().Elem().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += referredSize(iter.Key(), seen) + referredSize(iter.Value(), seen)
+		}
+		return size
+	}
+	return 0
+}
+
+// SizeReportingCache keeps track of the memory used by the cached values as
+// measured by the sizer set with WithSizer, ReflectSizer without it.
+type SizeReportingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	usage atomic.Int64
+}
+
+func NewSizeReportingCache[K comparable, T any](opts ...Option[K, T]) (*SizeReportingCache[K, T], error) {
+	c := &SizeReportingCache[K, T]{}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		if o.sizer == nil {
+			o.sizer = ReflectSizer[T]{}
+		}
+		o.onSet = func(e *entry[K, T]) {
+			c.usage.Add(e.cost)
+		}
+		o.onExit = func(e *entry[K, T]) {
+			c.usage.Add(-e.cost)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// MemoryUsage returns the approximate number of bytes used by cached values.
+func (c *SizeReportingCache[K, T]) MemoryUsage() int64 {
+	return c.usage.Load()
+}
+
+// WithTTLBySize sets the TTL of entries from the size of their values
+// measured by the sizer set with WithSizer.
+func WithTTLBySize[K comparable, T any](fn func(size int64) time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.ttlBySize = fn
+	}
+}
+
+// LinearTTLCurve returns a TTL function for WithTTLBySize which interpolates
+// linearly from maxTTL for empty values to minTTL for values of maxSize and
+// larger.
+func LinearTTLCurve(maxSize int64, minTTL, maxTTL time.Duration) func(size int64) time.Duration {
+	return func(size int64) time.Duration {
+		if size >= maxSize {
+			return minTTL
+		}
+		if size <= 0 {
+			return maxTTL
+		}
+		return maxTTL - time.Duration(float64(maxTTL-minTTL)*float64(size)/float64(maxSize))
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestReflectSizer(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	u := user{Name: "ab", Tags: []string{"x", "yz"}}
+	if got, want := (ReflectSizer[user]{}).Size(u), int64(unsafe.Sizeof(u)+2+2*unsafe.Sizeof("")+3); got != want {
+		t.Errorf("struct size %d, want %d", got, want)
+	}
+
+	ints := make([]int64, 2, 4)
+	if got, want := (ReflectSizer[[]int64]{}).Size(ints), int64(unsafe.Sizeof(ints)+4*8); got != want {
+		t.Errorf("slice size %d, want %d", got, want)
+	}
+
+	if got, want := (ReflectSizer[string]{}).Size("hello"), int64(unsafe.Sizeof("")+5); got != want {
+		t.Errorf("string size %d, want %d", got, want)
+	}
+}
+
+func TestSizeReportingCacheDefaultsToReflectSizer(t *testing.T) {
+	c, err := NewSizeReportingCache[string, string]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("k", "hello")
+	if got, want := c.MemoryUsage(), (ReflectSizer[string]{}).Size("hello"); got != want {
+		t.Errorf("memory usage %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotInBatch = errors.New("batch reader returned no value for the key")
+
+// BatchReader reads the values of several keys at once, keys missing from
+// the returned map are not cached.
+type BatchReader[K comparable, T any] func(keys []K) (map[K]T, error)
+
+type batchRequest[K comparable, T any] struct {
+	key    K
+	result chan batchResult[T]
+}
+
+type batchResult[T any] struct {
+	value T
+	err   error
+}
+
+// SmartBatchingCache reads the keys missed within window of the first miss
+// with a single call of the batch reader. Callers wait up to window plus the
+// duration of the batch read for their value.
+type SmartBatchingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	batchReader BatchReader[K, T]
+	window      time.Duration
+	requests    chan batchRequest[K, T]
+	stop        chan struct{}
+}
+
+func NewCacheWithSmartBatching[K comparable, T any](batchReader BatchReader[K, T], window time.Duration, opts ...Option[K, T]) (*SmartBatchingCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := &SmartBatchingCache[K, T]{
+		ristrettoCache: cache,
+		batchReader:    batchReader,
+		window:         window,
+		requests:       make(chan batchRequest[K, T]),
+		stop:           make(chan struct{}),
+	}
+	go c.collect()
+	return c, nil
+}
+
+// Load returns the cached value, reading it with the next batch on a miss.
+// It returns ErrNotInBatch if the batch reader did not return the key and
+// ErrDraining on misses after Close.
+func (c *SmartBatchingCache[K, T]) Load(key K) (T, error) {
+	return c.LoadOrStoreE(key, func() (T, error) {
+		result := make(chan batchResult[T], 1)
+		select {
+		case c.requests <- batchRequest[K, T]{key: key, result: result}:
+		case <-c.stop:
+			var zero T
+			return zero, ErrDraining
+		}
+		r := <-result
+		return r.value, r.err
+	})
+}
+
+// Close stops batching, the requests collected so far are still read.
+func (c *SmartBatchingCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *SmartBatchingCache[K, T]) collect() {
+	for {
+		var batch []batchRequest[K, T]
+		select {
+		case r := <-c.requests:
+			batch = append(batch, r)
+		case <-c.stop:
+			return
+		}
+
+		timer := time.NewTimer(c.window)
+	collecting:
+		for {
+			select {
+			case r := <-c.requests:
+				batch = append(batch, r)
+			case <-timer.C:
+				break collecting
+			case <-c.stop:
+				timer.Stop()
+				break collecting
+			}
+		}
+
+		// read while the next batch is collected
+		go c.read(batch)
+	}
+}
+
+func (c *SmartBatchingCache[K, T]) read(batch []batchRequest[K, T]) {
+	keys := make([]K, 0, len(batch))
+	seen := make(map[K]bool, len(batch))
+	for _, r := range batch {
+		if !seen[r.key] {
+			seen[r.key] = true
+			keys = append(keys, r.key)
+		}
+	}
+
+	values, err := c.batchReader(keys)
+	for _, r := range batch {
+		if err != nil {
+			r.result <- batchResult[T]{err: err}
+			continue
+		}
+		value, ok := values[r.key]
+		if !ok {
+			r.result <- batchResult[T]{err: ErrNotInBatch}
+			continue
+		}
+		r.result <- batchResult[T]{value: value}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minEvictionAge is the age at which SoftLimitCache stops halving the age of
+// entries it evicts.
+const minEvictionAge = time.Millisecond
+
+// WithSoftMaxCost sets the cost from which SoftLimitCache evicts entries in
+// the background.
+func WithSoftMaxCost[K comparable, T any](softMaxCost int64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.softMaxCost = softMaxCost
+	}
+}
+
+// SoftLimitCache evicts entries in the background once their total cost
+// exceeds the soft maximum cost, before ristretto has to evict them while
+// storing new entries at the maximum cost. It first evicts entries older
+// than half the default TTL and halves the age until the cost is below the
+// soft maximum again.
+type SoftLimitCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	cost    atomic.Int64
+	trigger chan struct{}
+	stop    chan struct{}
+	// entries holds the stored entries by key, ristretto cannot be iterated
+	entries sync.Map
+}
+
+func NewCacheWithSoftLimit[K comparable, T any](softMaxCost int64, opts ...Option[K, T]) (*SoftLimitCache[K, T], error) {
+	c := &SoftLimitCache[K, T]{
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	cache, err := NewCache(append(opts, WithSoftMaxCost[K, T](softMaxCost), func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.entries.Store(e.key, e)
+			if c.cost.Add(e.cost) > c.softMaxCost {
+				select {
+				case c.trigger <- struct{}{}:
+				default:
+				}
+			}
+		}
+		o.onExit = func(e *entry[K, T]) {
+			// the key may have been stored again in the meantime
+			c.entries.CompareAndDelete(e.key, e)
+			c.cost.Add(-e.cost)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+
+	go c.evictLoop()
+	return c, nil
+}
+
+// Cost returns the total cost of the cached entries.
+func (c *SoftLimitCache[K, T]) Cost() int64 {
+	return c.cost.Load()
+}
+
+// EvictOlderThan deletes the entries computed more than age ago and returns
+// their number.
+func (c *SoftLimitCache[K, T]) EvictOlderThan(age time.Duration) int {
+	cutoff := time.Now().Add(-age)
+	var evicted int
+	c.entries.Range(func(key, value any) bool {
+		if e := value.(*entry[K, T]); e.computedAt.Before(cutoff) {
+			c.Delete(e.key)
+			evicted++
+		}
+		return true
+	})
+	c.cache.Wait()
+	return evicted
+}
+
+func (c *SoftLimitCache[K, T]) Close() {
+	close(c.stop)
+	c.ristrettoCache.Close()
+}
+
+func (c *SoftLimitCache[K, T]) evictLoop() {
+	for {
+		select {
+		case <-c.trigger:
+		case <-c.stop:
+			return
+		}
+
+		for age := c.defaultTTL / 2; c.Cost() > c.softMaxCost && age >= minEvictionAge; age /= 2 {
+			c.EvictOlderThan(age)
+		}
+	}
+}
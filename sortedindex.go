@@ -0,0 +1,78 @@
+package main
+
+import (
+	"cmp"
+	"sync"
+	"time"
+
+	"github.com/google/btree"
+)
+
+// SecondarySortedIndex indexes the cached values by a field, so they can be
+// queried by ranges of the field. The index follows stores and removals of
+// entries.
+type SecondarySortedIndex[K comparable, T any, F cmp.Ordered] struct {
+	*ristrettoCache[K, T]
+	field func(value T) F
+
+	mu    sync.RWMutex
+	index *btree.BTreeG[indexItem[K, T, F]]
+}
+
+// indexItem orders entries by their field, entries with equal fields by
+// their unique versions.
+type indexItem[K comparable, T any, F cmp.Ordered] struct {
+	field F
+	e     *entry[K, T]
+}
+
+func (a indexItem[K, T, F]) less(b indexItem[K, T, F]) bool {
+	if c := cmp.Compare(a.field, b.field); c != 0 {
+		return c < 0
+	}
+	return a.e.version < b.e.version
+}
+
+func NewCacheWithSecondarySortedIndex[K comparable, T any, F cmp.Ordered](field func(value T) F, opts ...Option[K, T]) (*SecondarySortedIndex[K, T, F], error) {
+	c := &SecondarySortedIndex[K, T, F]{
+		field: field,
+		index: btree.NewG(32, indexItem[K, T, F].less),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.index.ReplaceOrInsert(indexItem[K, T, F]{field: c.field(e.value), e: e})
+		}
+		o.onExit = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.index.Delete(indexItem[K, T, F]{field: c.field(e.value), e: e})
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// RangeQuery returns the cached values whose field is within [min, max]
+// ordered by the field.
+func (c *SecondarySortedIndex[K, T, F]) RangeQuery(min, max F) []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	var values []T
+	c.index.AscendGreaterOrEqual(indexItem[K, T, F]{field: min, e: &entry[K, T]{}}, func(item indexItem[K, T, F]) bool {
+		if item.field > max {
+			return false
+		}
+		if item.e.ttl <= 0 || now.Before(item.e.expiresAt) {
+			values = append(values, item.e.value)
+		}
+		return true
+	})
+	return values
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSortedIndexRangeQuery(t *testing.T) {
+	c, err := NewCacheWithSecondarySortedIndex[string, int](func(value int) int { return value })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const n = 10000
+	for i := 0; i < n; i++ {
+		if !c.SetDefault("key:"+strconv.Itoa(i), i) {
+			t.Fatalf("set %d dropped", i)
+		}
+	}
+	values := c.RangeQuery(4000, 4999)
+	if len(values) != 1000 {
+		t.Fatalf("got %d values in [4000, 4999]", len(values))
+	}
+	for i, value := range values {
+		if value != 4000+i {
+			t.Fatalf("got %d at %d, want the values in order", value, i)
+		}
+	}
+
+	// updated and deleted entries leave the index
+	c.SetDefault("key:4000", n)
+	c.Delete("key:4001")
+	values = c.RangeQuery(4000, 4002)
+	if len(values) != 1 || values[0] != 4002 {
+		t.Errorf("got %v after the update and delete, want [4002]", values)
+	}
+	if values := c.RangeQuery(n, n); len(values) != 1 {
+		t.Errorf("got %v for the updated value", values)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SQLQueryCache caches the results of a parameterized query returning a
+// single row, keyed by the query parameters. The row is scanned into T, so
+// the query must select a single column. Failed queries, including ones
+// returning no rows, are not cached.
+type SQLQueryCache[T any] struct {
+	*ristrettoCache[string, T]
+	db    *sql.DB
+	query string
+}
+
+func NewCacheWithQueryResultCaching[T any](db *sql.DB, query string, opts ...Option[string, T]) (*SQLQueryCache[T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLQueryCache[T]{
+		ristrettoCache: cache,
+		db:             db,
+		query:          query,
+	}, nil
+}
+
+// Query returns the cached result of the query with the args, running the
+// query on a miss.
+func (c *SQLQueryCache[T]) Query(ctx context.Context, args ...any) (T, error) {
+	return c.LoadOrStoreE(compoundKey(args), func() (T, error) {
+		var value T
+		err := c.db.QueryRowContext(ctx, c.query, args...).Scan(&value)
+		return value, err
+	})
+}
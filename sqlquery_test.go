@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDB answers single column queries by their first argument, the module
+// has no database driver to test with.
+type fakeDB struct {
+	rows    map[int64]string
+	queries atomic.Int32
+}
+
+type fakeConn struct{ db *fakeDB }
+
+type fakeStmt struct{ db *fakeDB }
+
+type fakeRows struct{ values []string }
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("no transactions") }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return 1 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("read only")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.queries.Add(1)
+	rows := &fakeRows{}
+	if value, ok := s.db.rows[args[0].(int64)]; ok {
+		rows.values = append(rows.values, value)
+	}
+	return rows, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func TestSQLQueryCache(t *testing.T) {
+	fake := &fakeDB{rows: map[int64]string{1: "ada", 2: "grace"}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	c, err := NewCacheWithQueryResultCaching[string](db, "SELECT name FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		for id, want := range fake.rows {
+			if got, err := c.Query(ctx, id); err != nil || got != want {
+				t.Errorf("Query(%d) = %q, %v, want %q", id, got, err, want)
+			}
+		}
+	}
+	if n := fake.queries.Load(); n != 2 {
+		t.Errorf("ran %d queries for 2 parameters", n)
+	}
+
+	// missing rows are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := c.Query(ctx, int64(3)); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("got %v, want %v", err, sql.ErrNoRows)
+		}
+	}
+	if n := fake.queries.Load(); n != 4 {
+		t.Errorf("ran %d queries, want the missing row queried again", n)
+	}
+}
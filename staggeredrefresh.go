@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// StaggeredRefreshCache offsets the refresh schedule of every key by a
+// random duration up to refreshJitter, so keys registered together are not
+// all read at the same time.
+type StaggeredRefreshCache[K comparable, T any] struct {
+	*ScheduledRefreshCache[K, T]
+	refreshJitter time.Duration
+}
+
+func NewCacheWithStaggeredRefresh[K comparable, T any](refreshJitter time.Duration, opts ...Option[K, T]) (*StaggeredRefreshCache[K, T], error) {
+	cache, err := NewCacheWithScheduledRefresh(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &StaggeredRefreshCache[K, T]{
+		ScheduledRefreshCache: cache,
+		refreshJitter:         refreshJitter,
+	}, nil
+}
+
+// WithScheduledRefreshAll refreshes every key with read every
+// nominalInterval, the first read of each key happens after the interval plus
+// its random offset.
+func (c *StaggeredRefreshCache[K, T]) WithScheduledRefreshAll(keys []K, nominalInterval time.Duration, read reader[T]) {
+	for _, key := range keys {
+		c.schedule(key, c.jitter(), nominalInterval, read)
+	}
+}
+
+// RegisterSchedule refreshes the key every interval after a random offset.
+func (c *StaggeredRefreshCache[K, T]) RegisterSchedule(key K, interval time.Duration, read reader[T]) {
+	c.schedule(key, c.jitter(), interval, read)
+}
+
+func (c *StaggeredRefreshCache[K, T]) jitter() time.Duration {
+	if c.refreshJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(c.refreshJitter) + 1))
+}
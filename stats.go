@@ -0,0 +1,18 @@
+package main
+
+// Stats holds counters of cache operations.
+type Stats struct {
+	Hits             uint64
+	Misses           uint64
+	DroppedEvictions uint64
+	FanoutErrors     uint64
+	Collisions       uint64
+	DryRunCalls      uint64
+	RemoteErrors     uint64
+	DLQDropped       uint64
+	WriteErrors      uint64
+	Evictions        uint64
+	// CompressionRatio is the size of the encoded values divided by their
+	// stored size, values below the minimum size count as stored uncompressed.
+	CompressionRatio float64
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// StrictOrderingCache runs the LoadOrStore calls missing the same key in the
+// order they arrived, sync.Mutex gives no such guarantee under contention.
+// Hits do not wait for the queue.
+type StrictOrderingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// queues holds a *fifoLock per key
+	queues sync.Map
+}
+
+func NewCacheWithStrictOrdering[K comparable, T any](opts ...Option[K, T]) (*StrictOrderingCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &StrictOrderingCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *StrictOrderingCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *StrictOrderingCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	if e, ok := c.get(key); ok {
+		if value, ok := c.hit(e, read); ok {
+			return value, nil
+		}
+	}
+
+	anyQueue, _ := c.queues.LoadOrStore(key, &fifoLock{waiters: list.New()})
+	queue := anyQueue.(*fifoLock)
+	queue.Lock()
+	defer queue.Unlock()
+
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+// fifoLock is a mutex granted to its waiters in the order they called Lock.
+type fifoLock struct {
+	mu   sync.Mutex
+	held bool
+	// waiters holds a chan struct{} per waiter, closed once it holds the
+	// lock
+	waiters *list.List
+}
+
+func (l *fifoLock) Lock() {
+	l.mu.Lock()
+	if !l.held {
+		l.held = true
+		l.mu.Unlock()
+		return
+	}
+	granted := make(chan struct{})
+	l.waiters.PushBack(granted)
+	l.mu.Unlock()
+	<-granted
+}
+
+// Unlock hands the lock over to the longest waiting caller, if any.
+func (l *fifoLock) Unlock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	front := l.waiters.Front()
+	if front == nil {
+		l.held = false
+		return
+	}
+	close(l.waiters.Remove(front).(chan struct{}))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waiting returns the number of callers waiting for the lock.
+func (l *fifoLock) waiting() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waiters.Len()
+}
+
+func TestStrictOrderingRunsReadersInArrivalOrder(t *testing.T) {
+	// values are never stored, so every call runs its reader
+	c, err := NewCacheWithStrictOrdering(WithAllowIf(func(string, int) bool { return false }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var mu sync.Mutex
+	var order []int
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.LoadOrStore("k", func() int {
+			close(started)
+			<-release
+			return 0
+		})
+	}()
+	<-started
+
+	anyQueue, _ := c.queues.Load("k")
+	queue := anyQueue.(*fifoLock)
+	for i := 1; i <= 20; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.LoadOrStore("k", func() int {
+				mu.Lock()
+				order = append(order, i)
+				mu.Unlock()
+				return i
+			})
+		}()
+		// start the next caller only once this one is queued
+		for queue.waiting() < i {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	want := make([]int, 20)
+	for i := range want {
+		want[i] = i + 1
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("readers ran in order %v", order)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "time"
+
+// StrictTTLCache checks the expiry of entries itself on every access and
+// deletes expired entries right away. Ristretto already hides expired
+// entries, but entries kept by WithStaleTTL would otherwise stay in the cache
+// until their stale TTL passes.
+type StrictTTLCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewCacheWithStrictTTL[K comparable, T any](opts ...Option[K, T]) (*StrictTTLCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &StrictTTLCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *StrictTTLCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *StrictTTLCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	c.deleteExpired(key)
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+func (c *StrictTTLCache[K, T]) Peek(key K) (T, bool) {
+	c.deleteExpired(key)
+	return c.ristrettoCache.Peek(key)
+}
+
+func (c *StrictTTLCache[K, T]) deleteExpired(key K) {
+	if e, ok := c.getStale(key); ok && time.Now().After(e.expiresAt) {
+		c.Delete(key)
+	}
+}
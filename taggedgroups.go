@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var ErrUnknownGroup = errors.New("unknown cache group")
+
+// TaggedGroupCache gives every group of entries its own cost budget. Each
+// group is backed by its own ristretto instance, so a full group makes
+// ristretto evict entries of the same group and never of other groups.
+type TaggedGroupCache[K comparable, T any] struct {
+	groups map[string]*cacheGroup[K, T]
+}
+
+type cacheGroup[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	usage atomic.Int64
+}
+
+// NewCacheWithTaggedGroups creates a group for every entry of budgets, which
+// maps group names to their max costs.
+func NewCacheWithTaggedGroups[K comparable, T any](budgets map[string]int64, opts ...Option[K, T]) (*TaggedGroupCache[K, T], error) {
+	c := &TaggedGroupCache[K, T]{groups: make(map[string]*cacheGroup[K, T], len(budgets))}
+	for name, budget := range budgets {
+		group := &cacheGroup[K, T]{}
+		cache, err := NewCache(append(opts, WithMaxCost[K, T](budget), func(o *options[K, T]) {
+			o.onSet = func(e *entry[K, T]) {
+				group.usage.Add(e.cost)
+			}
+			o.onExit = func(e *entry[K, T]) {
+				group.usage.Add(-e.cost)
+			}
+		})...)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		group.ristrettoCache = cache
+		c.groups[name] = group
+	}
+	return c, nil
+}
+
+func (c *TaggedGroupCache[K, T]) LoadOrStore(group string, key K, read reader[T]) (T, error) {
+	return c.LoadOrStoreE(group, key, read.withError())
+}
+
+func (c *TaggedGroupCache[K, T]) LoadOrStoreE(group string, key K, read readerE[T]) (T, error) {
+	g, ok := c.groups[group]
+	if !ok {
+		var zero T
+		return zero, ErrUnknownGroup
+	}
+	return g.LoadOrStoreE(key, read)
+}
+
+func (c *TaggedGroupCache[K, T]) Peek(group string, key K) (T, bool) {
+	g, ok := c.groups[group]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return g.Peek(key)
+}
+
+func (c *TaggedGroupCache[K, T]) Delete(group string, key K) {
+	if g, ok := c.groups[group]; ok {
+		g.Delete(key)
+	}
+}
+
+// GroupUsage returns the cost of the entries cached in the group.
+func (c *TaggedGroupCache[K, T]) GroupUsage(group string) int64 {
+	g, ok := c.groups[group]
+	if !ok {
+		return 0
+	}
+	return g.usage.Load()
+}
+
+func (c *TaggedGroupCache[K, T]) Close() {
+	for _, g := range c.groups {
+		g.Close()
+	}
+}
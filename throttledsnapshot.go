@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ThrottledSnapshotCache writes snapshots at a limited number of entries per
+// second, so writing a large cache does not starve the other goroutines.
+// Entries stored or deleted while a snapshot is written may or may not be
+// included in it.
+type ThrottledSnapshotCache[K comparable, T any] struct {
+	*LZ4SnapshotCache[K, T]
+	maxEntriesPerSec int
+}
+
+func NewCacheWithThrottledSnapshot[K comparable, T any](maxEntriesPerSec int, opts ...Option[K, T]) (*ThrottledSnapshotCache[K, T], error) {
+	cache, err := NewCacheWithLZ4Snapshot(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ThrottledSnapshotCache[K, T]{
+		LZ4SnapshotCache: cache,
+		maxEntriesPerSec: maxEntriesPerSec,
+	}, nil
+}
+
+// Snapshot writes the snapshot at the rate the cache was created with.
+func (c *ThrottledSnapshotCache[K, T]) Snapshot(w io.Writer) (SnapshotStats, error) {
+	return c.ThrottledSnapshot(context.Background(), w, c.maxEntriesPerSec)
+}
+
+// ThrottledSnapshot writes the snapshot at maxEntriesPerSec entries per
+// second, it returns ctx.Err() if ctx is done before the snapshot is
+// complete. The snapshot can be read with Restore.
+func (c *ThrottledSnapshotCache[K, T]) ThrottledSnapshot(ctx context.Context, w io.Writer, maxEntriesPerSec int) (SnapshotStats, error) {
+	limiter := rate.NewLimiter(rate.Limit(maxEntriesPerSec), 1)
+	return c.snapshot(w, nil, func() error {
+		return limiter.Wait(ctx)
+	})
+}
+
+// ForEach calls fn for the cached values which have not expired until fn
+// returns false.
+func (c *ThrottledSnapshotCache[K, T]) ForEach(fn func(key K, value T) bool) {
+	c.entries.Range(func(_, value any) bool {
+		e := value.(*entry[K, T])
+		if e.ttl > 0 && time.Now().After(e.expiresAt) {
+			return true
+		}
+		return fn(e.key, e.value)
+	})
+}
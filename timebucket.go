@@ -0,0 +1,31 @@
+package main
+
+import "time"
+
+// TimeBucketCache caches one value per time bucket, e.g. per-minute
+// aggregates. Buckets are evicted two bucket durations after being stored.
+type TimeBucketCache[T any] struct {
+	cache          *ristrettoCache[int64, T]
+	bucketDuration time.Duration
+	now            func() time.Time
+}
+
+func NewTimeBucketCache[T any](bucketDuration time.Duration) (*TimeBucketCache[T], error) {
+	cache, err := NewCache[int64, T]()
+	if err != nil {
+		return nil, err
+	}
+	cache.defaultTTL = 2 * bucketDuration
+	return &TimeBucketCache[T]{
+		cache:          cache,
+		bucketDuration: bucketDuration,
+		now:            time.Now,
+	}, nil
+}
+
+// LoadCurrentBucket returns the value of the bucket the current time falls
+// into, reading it if the bucket has not been loaded yet.
+func (c *TimeBucketCache[T]) LoadCurrentBucket(read reader[T]) T {
+	bucket := c.now().Truncate(c.bucketDuration)
+	return c.cache.LoadOrStore(bucket.UnixNano(), read)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock which only moves when advanced.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTimeBucketAcrossBoundaries(t *testing.T) {
+	c, err := NewTimeBucketCache[int](time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cache.Close()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+	c.now = clock.Now
+
+	reads := 0
+	read := func() int {
+		reads++
+		return reads
+	}
+	for bucket := 1; bucket <= 3; bucket++ {
+		for i := 0; i < 4; i++ {
+			if value := c.LoadCurrentBucket(read); value != bucket {
+				t.Errorf("got %d at %s, want %d", value, clock.Now().Format(time.TimeOnly), bucket)
+			}
+			clock.Advance(15 * time.Minute)
+		}
+	}
+	if reads != 3 {
+		t.Errorf("read %d times for 3 buckets", reads)
+	}
+}
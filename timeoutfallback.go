@@ -0,0 +1,84 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// WithFallbackTTL sets the TTL of values read by the fallback reader of
+// TimeoutFallbackCache, a tenth of the default TTL by default.
+func WithFallbackTTL[K comparable, T any](ttl time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.fallbackTTL = ttl
+	}
+}
+
+// TimeoutFallbackCache returns the value of the fallback reader when the
+// reader of a miss does not return within timeout. The fallback value is
+// cached with the shorter fallback TTL and replaced by the value of the
+// reader once it returns.
+type TimeoutFallbackCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	timeout  time.Duration
+	fallback reader[T]
+	// fallbacks holds the keys whose miss is storing the fallback value
+	fallbacks sync.Map
+}
+
+func NewCacheWithTimeoutFallback[K comparable, T any](timeout time.Duration, fallback reader[T], opts ...Option[K, T]) (*TimeoutFallbackCache[K, T], error) {
+	c := &TimeoutFallbackCache[K, T]{timeout: timeout, fallback: fallback}
+	cache, err := NewCache(append(append([]Option[K, T]{WithFallbackTTL[K, T](ttl / 10)}, opts...), func(o *options[K, T]) {
+		o.invokeMiss = c.invokeMiss
+		o.ttlOf = func(e *entry[K, T]) time.Duration {
+			if _, ok := c.fallbacks.LoadAndDelete(e.key); ok {
+				return c.fallbackTTL
+			}
+			return e.ttl
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// invokeMiss returns the value of the fallback reader if read does not
+// return within the timeout, the value of read is stored once it returns.
+func (c *TimeoutFallbackCache[K, T]) invokeMiss(key K, read readerE[T]) (T, error) {
+	// a fallback value rejected by the options of the last miss is left
+	c.fallbacks.Delete(key)
+
+	results := make(chan timeoutResult[T], 1)
+	go func() {
+		value, err := c.invoke(read)
+		results <- timeoutResult[T]{value, err}
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.value, r.err
+	case <-timer.C:
+	}
+
+	go func() {
+		r := <-results
+		if r.err != nil {
+			return
+		}
+		// waits for the miss to store the fallback value
+		lock := c.keyLock(key)
+		lock.Lock()
+		defer lock.Unlock()
+		c.storeRead(key, r.value)
+	}()
+	c.fallbacks.Store(key, struct{}{})
+	return c.fallback(), nil
+}
+
+type timeoutResult[T any] struct {
+	value T
+	err   error
+}
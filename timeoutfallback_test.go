@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFallbackLoadOrStoreE(t *testing.T) {
+	c, err := NewCacheWithTimeoutFallback[string](10*time.Millisecond, func() string { return "fallback" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	release := make(chan struct{})
+	value, err := c.LoadOrStoreE("k", func() (string, error) {
+		<-release
+		return "slow", nil
+	})
+	if err != nil || value != "fallback" {
+		t.Fatalf("LoadOrStoreE = %q, %v, want the fallback value", value, err)
+	}
+	e, ok := c.get("k")
+	if !ok || e.value != "fallback" || e.ttl != c.fallbackTTL {
+		t.Fatalf("fallback value not cached with the fallback TTL")
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if value, _ := c.Peek("k"); value == "slow" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("value of the slow reader not stored")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if e, _ := c.get("k"); e.ttl != c.defaultTTL {
+		t.Errorf("value of the slow reader cached with TTL %v, want %v", e.ttl, c.defaultTTL)
+	}
+}
+
+var errNegative = errors.New("negative value")
+
+func TestTimeoutFallbackAppliesValidators(t *testing.T) {
+	c, err := NewCacheWithTimeoutFallback(time.Second, func() int { return 0 }, WithValueValidator[string](func(value int) error {
+		if value < 0 {
+			return errNegative
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.LoadOrStoreE("k", func() (int, error) { return -1, nil }); !errors.Is(err, errNegative) {
+		t.Errorf("got %v, want %v", err, errNegative)
+	}
+	if _, ok := c.Peek("k"); ok {
+		t.Error("invalid value cached")
+	}
+}
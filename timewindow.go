@@ -0,0 +1,22 @@
+package main
+
+import "time"
+
+// TimeWindowCache returns only values computed within the last window, older
+// values are read again even if they have not expired yet. Unlike the TTL,
+// the window does not affect how long values are kept in the cache.
+type TimeWindowCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+}
+
+func NewTimeWindowCache[K comparable, T any](window time.Duration, opts ...Option[K, T]) (*TimeWindowCache[K, T], error) {
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.isFresh = func(e *entry[K, T]) bool {
+			return time.Since(e.computedAt) < window
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	return &TimeWindowCache[K, T]{ristrettoCache: cache}, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type traceOptions struct {
+	minDuration time.Duration
+}
+
+type TraceOption func(*traceOptions)
+
+// WithTraceFilter logs only operations which took at least minDuration.
+func WithTraceFilter(minDuration time.Duration) TraceOption {
+	return func(o *traceOptions) {
+		o.minDuration = minDuration
+	}
+}
+
+// TracedCache logs every operation of the wrapped cache with its duration at
+// the debug level.
+type TracedCache[K comparable, T any] struct {
+	cache  Cache[K, T]
+	logger *slog.Logger
+	traceOptions
+}
+
+func NewTraced[K comparable, T any](cache Cache[K, T], logger *slog.Logger, opts ...TraceOption) *TracedCache[K, T] {
+	c := &TracedCache[K, T]{
+		cache:  cache,
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(&c.traceOptions)
+	}
+	return c
+}
+
+func (c *TracedCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	start := time.Now()
+	value, hit := loadOrStoreHit(c.cache, key, read)
+	c.trace(key, "LoadOrStore", time.Since(start), hit)
+	return value
+}
+
+// loadOrStoreHit calls LoadOrStore of the cache and reports whether the
+// value was cached. The base cache reports its lookup, other caches are
+// peeked at first: a flag set by the reader would also be set by audits,
+// which call the reader of hits in the background.
+func loadOrStoreHit[K comparable, T any](cache Cache[K, T], key K, read reader[T]) (T, bool) {
+	if c, ok := cache.(*ristrettoCache[K, T]); ok {
+		return c.loadOrStoreHit(key, read)
+	}
+	_, hit := cache.Peek(key)
+	return cache.LoadOrStore(key, read), hit
+}
+
+func (c *TracedCache[K, T]) Peek(key K) (T, bool) {
+	start := time.Now()
+	value, ok := c.cache.Peek(key)
+	c.trace(key, "Peek", time.Since(start), ok)
+	return value, ok
+}
+
+func (c *TracedCache[K, T]) SetDefault(key K, value T) bool {
+	start := time.Now()
+	ok := c.cache.SetDefault(key, value)
+	c.trace(key, "SetDefault", time.Since(start), false)
+	return ok
+}
+
+func (c *TracedCache[K, T]) Delete(key K) {
+	start := time.Now()
+	c.cache.Delete(key)
+	c.trace(key, "Delete", time.Since(start), false)
+}
+
+func (c *TracedCache[K, T]) trace(key K, operation string, duration time.Duration, hit bool) {
+	if duration < c.minDuration {
+		return
+	}
+	c.logger.LogAttrs(context.Background(), slog.LevelDebug, "cache operation",
+		slog.Any("key", key),
+		slog.String("operation", operation),
+		slog.Duration("duration", duration),
+		slog.Bool("hit", hit),
+	)
+}
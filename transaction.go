@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrTransactionConflict = errors.New("transaction conflicts with a concurrent change")
+
+// TransactionCache supports updating several keys at once with transactions.
+// Reads wait for the commits in progress, so they never see the changes of
+// a transaction partially applied.
+type TransactionCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	// commitMu is held by commits while they apply their changes
+	commitMu sync.RWMutex
+}
+
+func NewTransactionCache[K comparable, T any](opts ...Option[K, T]) (*TransactionCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionCache[K, T]{ristrettoCache: cache}, nil
+}
+
+func (c *TransactionCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *TransactionCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	c.awaitCommits()
+	return c.ristrettoCache.LoadOrStoreE(key, read)
+}
+
+// SetDefault waits for the commits in progress, commits wait for it in turn
+// so its change is either seen by their conflict check or applied after
+// them.
+func (c *TransactionCache[K, T]) SetDefault(key K, value T) bool {
+	c.commitMu.RLock()
+	defer c.commitMu.RUnlock()
+	return c.ristrettoCache.SetDefault(key, value)
+}
+
+func (c *TransactionCache[K, T]) Peek(key K) (T, bool) {
+	c.awaitCommits()
+	return c.ristrettoCache.Peek(key)
+}
+
+// awaitCommits waits until the commits in progress are applied. A value
+// read afterwards may come from a commit started meanwhile, but then every
+// later read waits for that commit.
+func (c *TransactionCache[K, T]) awaitCommits() {
+	c.commitMu.RLock()
+	c.commitMu.RUnlock()
+}
+
+type txChange[T any] struct {
+	value  T
+	delete bool
+}
+
+// Transaction collects changes of several keys which are applied at once by
+// Commit. The version of every key is recorded when the transaction first
+// touches it, the commit fails if any of them has changed in the meantime.
+type Transaction[K comparable, T any] struct {
+	cache    *TransactionCache[K, T]
+	versions map[K]uint64
+	changes  map[K]txChange[T]
+}
+
+func (c *TransactionCache[K, T]) Begin() *Transaction[K, T] {
+	return &Transaction[K, T]{
+		cache:    c,
+		versions: map[K]uint64{},
+		changes:  map[K]txChange[T]{},
+	}
+}
+
+// Get returns the value as seen by the transaction.
+func (tx *Transaction[K, T]) Get(key K) (T, bool) {
+	if change, ok := tx.changes[key]; ok {
+		return change.value, !change.delete
+	}
+	tx.track(key)
+	return tx.cache.Peek(key)
+}
+
+func (tx *Transaction[K, T]) Set(key K, value T) {
+	tx.track(key)
+	tx.changes[key] = txChange[T]{value: value}
+}
+
+func (tx *Transaction[K, T]) Delete(key K) {
+	tx.track(key)
+	tx.changes[key] = txChange[T]{delete: true}
+}
+
+// Commit applies the changes while holding the locks of all keys, reads of
+// the cache wait until the commit is done.
+func (tx *Transaction[K, T]) Commit() error {
+	keys := make([]K, 0, len(tx.versions))
+	for key := range tx.versions {
+		keys = append(keys, key)
+	}
+	unlock := tx.cache.lockKeys(keys)
+	defer unlock()
+
+	tx.cache.commitMu.Lock()
+	defer tx.cache.commitMu.Unlock()
+	for key, version := range tx.versions {
+		if tx.cache.version(key) != version {
+			return ErrTransactionConflict
+		}
+	}
+	for key, change := range tx.changes {
+		if change.delete {
+			tx.cache.Delete(key)
+		} else {
+			tx.cache.set(key, change.value)
+		}
+	}
+	tx.cache.cache.Wait()
+	return nil
+}
+
+func (tx *Transaction[K, T]) track(key K) {
+	if _, ok := tx.versions[key]; !ok {
+		tx.versions[key] = tx.cache.version(key)
+	}
+}
+
+// version returns the version of the cached value, 0 if it is not cached.
+func (c *TransactionCache[K, T]) version(key K) uint64 {
+	if e, ok := c.get(key); ok {
+		return e.version
+	}
+	return 0
+}
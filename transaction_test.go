@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransactionConflict(t *testing.T) {
+	c, err := NewTransactionCache[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("a", 1)
+	tx := c.Begin()
+	if value, _ := tx.Get("a"); value != 1 {
+		t.Fatalf("Get() = %d", value)
+	}
+	tx.Set("a", 2)
+	c.SetDefault("a", 3)
+	if err := tx.Commit(); !errors.Is(err, ErrTransactionConflict) {
+		t.Errorf("got %v, want %v", err, ErrTransactionConflict)
+	}
+	if value, _ := c.Peek("a"); value != 3 {
+		t.Errorf("conflicting commit applied, a = %d", value)
+	}
+}
+
+func TestTransactionCommitIsNotSeenPartially(t *testing.T) {
+	c, err := NewTransactionCache[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	const commits = 500
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= commits; i++ {
+			tx := c.Begin()
+			tx.Set("a", i)
+			tx.Set("b", i)
+			if err := tx.Commit(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for done := false; !done; {
+		// b is read after a, so it is at least as new as a
+		a, _ := c.Peek("a")
+		b, _ := c.Peek("b")
+		if b < a {
+			t.Fatalf("read a = %d before b = %d", a, b)
+		}
+		done = a == commits
+	}
+	wg.Wait()
+}
+
+func TestTransactionSetDefaultWaitsForCommits(t *testing.T) {
+	c, err := NewTransactionCache[string, int]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// a commit in progress
+	c.commitMu.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.SetDefault("a", 1)
+	}()
+	select {
+	case <-done:
+		t.Fatal("SetDefault applied during a commit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.commitMu.Unlock()
+	<-done
+	if value, _ := c.Peek("a"); value != 1 {
+		t.Errorf("got %d, want 1", value)
+	}
+}
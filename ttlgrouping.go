@@ -0,0 +1,55 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// TTLGroupingCache rounds the TTL of every entry up to the next of the
+// bucket TTLs, e.g. 1s, 5s, 30s and 5m, so entries have only a few distinct
+// TTLs. TTLs above the largest bucket are kept. Rounding does not align the
+// expiry times, every entry still expires its rounded TTL after it was
+// stored.
+type TTLGroupingCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	buckets []time.Duration
+}
+
+func NewCacheWithTTLGrouping[K comparable, T any](buckets []time.Duration, opts ...Option[K, T]) (*TTLGroupingCache[K, T], error) {
+	c := &TTLGroupingCache[K, T]{buckets: slices.Clone(buckets)}
+	slices.Sort(c.buckets)
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.ttlOf = func(e *entry[K, T]) time.Duration {
+			return c.group(e.ttl)
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// SetWithTTL stores the value with ttl rounded up to the next bucket TTL and
+// reports whether it was accepted by ristretto.
+func (c *TTLGroupingCache[K, T]) SetWithTTL(key K, value T, ttl time.Duration) bool {
+	if c.keySchema != nil && !c.keySchema(key) {
+		return false
+	}
+	ok := c.setWithTTL(key, value, c.group(ttl))
+	c.cache.Wait()
+	return ok
+}
+
+// group returns the smallest bucket TTL not below ttl, zero TTLs never
+// expire and are kept.
+func (c *TTLGroupingCache[K, T]) group(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	i, _ := slices.BinarySearch(c.buckets, ttl)
+	if i == len(c.buckets) {
+		return ttl
+	}
+	return c.buckets[i]
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLGroupingRoundsTheDefaultTTL(t *testing.T) {
+	c, err := NewCacheWithTTLGrouping[string, string]([]time.Duration{5 * time.Second, time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	c.SetDefault("k", "v")
+	e, ok := c.getStale("k")
+	if !ok {
+		t.Fatal("entry not stored")
+	}
+	if e.ttl != 5*time.Second {
+		t.Errorf("default TTL %v rounded to %v, want 5s", ttl, e.ttl)
+	}
+}
+
+func TestTTLGroupingExpiresWithinOneBucket(t *testing.T) {
+	buckets := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond}
+	c, err := NewCacheWithTTLGrouping[string, string](buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	start := time.Now()
+	c.SetWithTTL("short", "v", 50*time.Millisecond)
+	c.SetWithTTL("long", "v", 150*time.Millisecond)
+
+	// the requested TTLs have passed, but not the bucket TTLs
+	time.Sleep(time.Until(start.Add(75 * time.Millisecond)))
+	if _, ok := c.Peek("short"); !ok {
+		t.Error("short expired before its bucket TTL")
+	}
+	time.Sleep(time.Until(start.Add(200 * time.Millisecond)))
+	if _, ok := c.Peek("short"); ok {
+		t.Error("short cached after its bucket TTL")
+	}
+	if _, ok := c.Peek("long"); !ok {
+		t.Error("long expired before its bucket TTL")
+	}
+	time.Sleep(time.Until(start.Add(350 * time.Millisecond)))
+	if _, ok := c.Peek("long"); ok {
+		t.Error("long cached after its bucket TTL")
+	}
+}
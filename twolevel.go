@@ -0,0 +1,96 @@
+package main
+
+import "errors"
+
+var ErrNotCached = errors.New("key is not cached")
+
+// TwoLevelCache keeps values read on a miss in the large L2 cache, L1 is
+// meant for a small set of hot keys and is managed with PromoteToL1 and
+// DemoteToL2.
+type TwoLevelCache[K comparable, T any] struct {
+	l1 *ristrettoCache[K, T]
+	l2 *ristrettoCache[K, T]
+}
+
+func NewTwoLevelCache[K comparable, T any](l1MaxCost int64, opts ...Option[K, T]) (*TwoLevelCache[K, T], error) {
+	l2, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	l1, err := NewCache(append(opts, WithMaxCost[K, T](l1MaxCost))...)
+	if err != nil {
+		l2.Close()
+		return nil, err
+	}
+	return &TwoLevelCache[K, T]{
+		l1: l1,
+		l2: l2,
+	}, nil
+}
+
+func (c *TwoLevelCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.l1.Peek(key); ok {
+		return value
+	}
+	return c.l2.LoadOrStore(key, read)
+}
+
+func (c *TwoLevelCache[K, T]) Peek(key K) (T, bool) {
+	if value, ok := c.l1.Peek(key); ok {
+		return value, true
+	}
+	return c.l2.Peek(key)
+}
+
+func (c *TwoLevelCache[K, T]) SetDefault(key K, value T) bool {
+	lock := c.l2.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.l1.Delete(key)
+	return c.l2.SetDefault(key, value)
+}
+
+func (c *TwoLevelCache[K, T]) Delete(key K) {
+	lock := c.l2.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	c.l1.Delete(key)
+	c.l2.Delete(key)
+}
+
+// PromoteToL1 copies the value from L2 to L1 without invoking any reader.
+func (c *TwoLevelCache[K, T]) PromoteToL1(key K) error {
+	lock := c.l2.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	value, ok := c.l2.Peek(key)
+	if !ok {
+		return ErrNotCached
+	}
+	c.l1.SetDefault(key, value)
+	return nil
+}
+
+// DemoteToL2 moves the value from L1 to L2. The value is stored in L2 before
+// it is deleted from L1, so concurrent readers always find it.
+func (c *TwoLevelCache[K, T]) DemoteToL2(key K) error {
+	lock := c.l2.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	value, ok := c.l1.Peek(key)
+	if !ok {
+		return ErrNotCached
+	}
+	c.l2.SetDefault(key, value)
+	c.l1.Delete(key)
+	return nil
+}
+
+func (c *TwoLevelCache[K, T]) Close() {
+	c.l1.Close()
+	c.l2.Close()
+}
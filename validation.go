@@ -0,0 +1,43 @@
+package main
+
+import "errors"
+
+var (
+	ErrZeroValue  = errors.New("value is the zero value")
+	ErrEmptySlice = errors.New("value is an empty slice")
+)
+
+// WithValueValidator checks values returned by the reader before caching
+// them. Values failing validation are not cached and LoadOrStoreE returns
+// the validation error. Validators of multiple WithValueValidator options
+// run in order.
+func WithValueValidator[K comparable, T any](fn func(value T) error) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.validators = append(o.validators, fn)
+	}
+}
+
+func NewCacheWithValueValidation[K comparable, T any](validator func(value T) error, opts ...Option[K, T]) (*ristrettoCache[K, T], error) {
+	return NewCache(append(opts, WithValueValidator[K](validator))...)
+}
+
+// NonZeroValidator rejects zero values.
+func NonZeroValidator[T comparable]() func(value T) error {
+	return func(value T) error {
+		var zero T
+		if value == zero {
+			return ErrZeroValue
+		}
+		return nil
+	}
+}
+
+// NonEmptySliceValidator rejects nil and empty slices.
+func NonEmptySliceValidator[T any]() func(value []T) error {
+	return func(value []T) error {
+		if len(value) == 0 {
+			return ErrEmptySlice
+		}
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "maps"
+
+// VersionVector counts the updates of a value per node ID.
+type VersionVector map[string]int64
+
+// Dominates reports whether vv has seen every update other has seen and at
+// least one more.
+func (vv VersionVector) Dominates(other VersionVector) bool {
+	for node, counter := range other {
+		if vv[node] < counter {
+			return false
+		}
+	}
+	for node, counter := range vv {
+		if counter > other[node] {
+			return true
+		}
+	}
+	return false
+}
+
+// Concurrent reports whether vv and other both have updates the other has
+// not seen.
+func (vv VersionVector) Concurrent(other VersionVector) bool {
+	return !vv.Dominates(other) && !other.Dominates(vv) && !maps.Equal(vv, other)
+}
+
+type vectoredValue[T any] struct {
+	value T
+	vv    VersionVector
+}
+
+// VersionVectorCache keeps a version vector with every value, so replicas
+// exchanging updates peer to peer can tell stale updates and concurrent ones
+// from newer values. Local updates count towards the node ID, values loaded
+// with LoadOrStore start with an empty vector.
+type VersionVectorCache[K comparable, T any] struct {
+	cache  *ristrettoCache[K, vectoredValue[T]]
+	nodeID string
+}
+
+func NewCacheWithVersionVector[K comparable, T any](nodeID string, opts ...Option[K, vectoredValue[T]]) (*VersionVectorCache[K, T], error) {
+	cache, err := NewCache(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionVectorCache[K, T]{cache: cache, nodeID: nodeID}, nil
+}
+
+func (c *VersionVectorCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	return c.cache.LoadOrStore(key, func() vectoredValue[T] {
+		return vectoredValue[T]{value: read(), vv: VersionVector{}}
+	}).value
+}
+
+// Get returns the cached value and a copy of its version vector.
+func (c *VersionVectorCache[K, T]) Get(key K) (T, VersionVector, bool) {
+	e, ok := c.cache.get(key)
+	if !ok {
+		var zero T
+		return zero, nil, false
+	}
+	return e.value.value, maps.Clone(e.value.vv), true
+}
+
+// Set stores a local update of the key and returns its version vector, to be
+// sent to the other replicas with the value.
+func (c *VersionVectorCache[K, T]) Set(key K, value T) VersionVector {
+	lock := c.cache.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// stored vectors are shared with readers and must not be modified
+	vv := VersionVector{}
+	if e, ok := c.cache.get(key); ok {
+		vv = maps.Clone(e.value.vv)
+	}
+	vv[c.nodeID]++
+	c.cache.SetDefault(key, vectoredValue[T]{value: value, vv: vv})
+	return maps.Clone(vv)
+}
+
+// Merge stores the remote value if its version vector dominates the local
+// one or the key is not cached. It returns false if the local value is newer
+// than, equal to or concurrent with the remote one.
+func (c *VersionVectorCache[K, T]) Merge(key K, remote T, remoteVV VersionVector) bool {
+	lock := c.cache.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if e, ok := c.cache.get(key); ok && !remoteVV.Dominates(e.value.vv) {
+		return false
+	}
+	c.cache.SetDefault(key, vectoredValue[T]{value: remote, vv: maps.Clone(remoteVV)})
+	return true
+}
+
+func (c *VersionVectorCache[K, T]) Delete(key K) {
+	c.cache.Delete(key)
+}
+
+func (c *VersionVectorCache[K, T]) Close() {
+	c.cache.Close()
+}
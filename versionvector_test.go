@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestVersionVectorDetectsConcurrentUpdates(t *testing.T) {
+	a, err := NewCacheWithVersionVector[string, string]("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := NewCacheWithVersionVector[string, string]("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// b applies a's update and then updates the key itself
+	vv := a.Set("k", "a1")
+	if !b.Merge("k", "a1", vv) {
+		t.Fatal("update of an uncached key not merged")
+	}
+	newer := b.Set("k", "b1")
+	if !a.Merge("k", "b1", newer) {
+		t.Error("dominating update not merged")
+	}
+	if a.Merge("k", "a1", vv) {
+		t.Error("stale update merged")
+	}
+	if a.Merge("k", "b1", newer) {
+		t.Error("equal update merged")
+	}
+
+	// both update the same version concurrently
+	fromA, fromB := a.Set("k", "a2"), b.Set("k", "b2")
+	if !fromA.Concurrent(fromB) {
+		t.Errorf("%v and %v not detected as concurrent", fromA, fromB)
+	}
+	if a.Merge("k", "b2", fromB) || b.Merge("k", "a2", fromA) {
+		t.Error("concurrent update merged")
+	}
+	for _, c := range []struct {
+		cache *VersionVectorCache[string, string]
+		want  string
+	}{{a, "a2"}, {b, "b2"}} {
+		if value, _, _ := c.cache.Get("k"); value != c.want {
+			t.Errorf("got %q, want the local %q kept", value, c.want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WithWALMaxSize sets the size at which WALCache starts a new WAL segment,
+// 64MB by default.
+func WithWALMaxSize[K comparable, T any](size int64) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.walMaxSize = size
+	}
+}
+
+type walRecord[K comparable, T any] struct {
+	Key   K
+	Value T
+	TTL   time.Duration
+	Time  time.Time
+	// Deleted marks a tombstone of a deleted key
+	Deleted bool
+}
+
+// WALCache appends every value stored in the cache and every deleted key to
+// a write-ahead log before applying it, so the cache can be restored with
+// Recover after a crash. The log is split into segments named
+// walPath.NNNNNN. Segments whose records have all expired are deleted when
+// the log rotates. Segments are not synced to disk, records written shortly
+// before the host crashes may be lost.
+type WALCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	walPath string
+
+	mu      sync.Mutex
+	segment *os.File
+	index   int
+	size    int64
+	// err is the first error of writing a tombstone, Delete cannot return it
+	err error
+}
+
+func NewCacheWithWAL[K comparable, T any](walPath string, opts ...Option[K, T]) (*WALCache[K, T], error) {
+	cache, err := NewCache(append([]Option[K, T]{WithWALMaxSize[K, T](64 << 20)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &WALCache[K, T]{
+		ristrettoCache: cache,
+		walPath:        walPath,
+	}
+
+	segments, err := walSegments(walPath)
+	if err != nil {
+		cache.Close()
+		return nil, err
+	}
+	if len(segments) > 0 {
+		// never append to an existing segment, it may end with a torn record
+		fmt.Sscanf(filepath.Ext(segments[len(segments)-1]), ".%d", &c.index)
+	}
+	if err := c.rotate(); err != nil {
+		cache.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *WALCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+// LoadOrStoreE returns the error of writing the WAL record, the value is not
+// cached in that case.
+func (c *WALCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, err := read()
+		if err != nil {
+			return value, err
+		}
+		return value, c.append(walRecord[K, T]{Key: key, Value: value, TTL: c.defaultTTL, Time: time.Now()})
+	}, read)
+	return value, err
+}
+
+// SetDefault reports false if the WAL record cannot be written, the value
+// is not cached then.
+func (c *WALCache[K, T]) SetDefault(key K, value T) bool {
+	if c.keySchema != nil && !c.keySchema(key) {
+		return false
+	}
+	if err := c.append(walRecord[K, T]{Key: key, Value: value, TTL: c.defaultTTL, Time: time.Now()}); err != nil {
+		return false
+	}
+	return c.ristrettoCache.SetDefault(key, value)
+}
+
+// Delete logs a tombstone for the key and deletes it. The key is deleted
+// even if the tombstone cannot be written, Close returns the first such
+// error.
+func (c *WALCache[K, T]) Delete(key K) {
+	if err := c.append(walRecord[K, T]{Key: key, TTL: c.defaultTTL, Time: time.Now(), Deleted: true}); err != nil {
+		c.mu.Lock()
+		if c.err == nil {
+			c.err = err
+		}
+		c.mu.Unlock()
+	}
+	c.ristrettoCache.Delete(key)
+}
+
+// Recover stores the records of the WAL at walPath which have not expired
+// yet in the cache, keys of tombstones are deleted.
+func (c *WALCache[K, T]) Recover(walPath string) error {
+	segments, err := walSegments(walPath)
+	if err != nil {
+		return err
+	}
+	// only the last record of a key is stored, ristretto drops sets of keys
+	// whose previous set is still buffered
+	records := make(map[K]walRecord[K, T])
+	for _, segment := range segments {
+		if err := c.replay(segment, records); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		if record.Deleted {
+			c.ristrettoCache.Delete(record.Key)
+			continue
+		}
+		expiresAt := record.Time.Add(record.TTL)
+		if time.Now().After(expiresAt) {
+			continue
+		}
+		e := c.newEntry(record.Key, record.Value)
+		e.computedAt = record.Time
+		e.expiresAt = expiresAt
+		e.ttl = time.Until(expiresAt)
+		c.setEntry(e)
+	}
+	c.cache.Wait()
+	return nil
+}
+
+func (c *WALCache[K, T]) Close() error {
+	c.ristrettoCache.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.segment.Close(); err != nil {
+		return err
+	}
+	return c.err
+}
+
+// replay reads the records of the segment into records by key.
+func (c *WALCache[K, T]) replay(segment string, records map[K]walRecord[K, T]) error {
+	f, err := os.Open(segment)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return err
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			// the last record was not written completely
+			return nil
+		}
+
+		var record walRecord[K, T]
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil {
+			return err
+		}
+		records[record.Key] = record
+	}
+}
+
+func (c *WALCache[K, T]) append(record walRecord[K, T]) error {
+	// every record is encoded on its own, so records can be decoded without
+	// the ones before them
+	var data bytes.Buffer
+	data.Write(make([]byte, 4))
+	if err := gob.NewEncoder(&data).Encode(&record); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(data.Bytes(), uint32(data.Len()-4))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size >= c.walMaxSize {
+		if err := c.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := c.segment.Write(data.Bytes())
+	c.size += int64(n)
+	return err
+}
+
+func (c *WALCache[K, T]) rotate() error {
+	if c.segment != nil {
+		if err := c.segment.Close(); err != nil {
+			return err
+		}
+	}
+
+	c.index++
+	segment, err := os.OpenFile(fmt.Sprintf("%s.%06d", c.walPath, c.index), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	c.segment = segment
+	c.size = 0
+
+	return c.deleteExpiredSegments()
+}
+
+// deleteExpiredSegments deletes the segments which were last written more
+// than a TTL ago, all their records have expired.
+func (c *WALCache[K, T]) deleteExpiredSegments() error {
+	segments, err := walSegments(c.walPath)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if segment == c.segment.Name() {
+			continue
+		}
+		info, err := os.Stat(segment)
+		if err != nil {
+			return err
+		}
+		if time.Since(info.ModTime()) > c.defaultTTL {
+			if err := os.Remove(segment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func walSegments(walPath string) ([]string, error) {
+	segments, err := filepath.Glob(walPath + ".[0-9][0-9][0-9][0-9][0-9][0-9]")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWALRecover(t *testing.T) {
+	walPath := filepath.Join(t.TempDir(), "wal")
+	c, err := NewCacheWithWAL[string, string](walPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.LoadOrStore("read", func() string { return "read" })
+	c.SetDefault("set", "set")
+	c.SetDefault("deleted", "deleted")
+	c.Delete("deleted")
+	c.SetDefault("updated", "old")
+	c.SetDefault("updated", "new")
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := NewCacheWithWAL[string, string](walPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.Close()
+	if err := recovered.Recover(walPath); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"read": "read", "set": "set", "updated": "new"} {
+		if value, _ := recovered.Peek(key); value != want {
+			t.Errorf("recovered %q for %s, want %q", value, key, want)
+		}
+	}
+	if value, ok := recovered.Peek("deleted"); ok {
+		t.Errorf("deleted key recovered with %q", value)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+// WildcardCache keeps its keys in a trie, so keys matching path.Match glob
+// patterns can be listed and deleted without scanning all keys.
+type WildcardCache[K ~string, T any] struct {
+	*ristrettoCache[K, T]
+
+	mu      sync.Mutex
+	keys    *trieNode
+	entries map[K]*entry[K, T]
+}
+
+type trieNode struct {
+	children map[byte]*trieNode
+	// leaf marks nodes ending a key
+	leaf bool
+}
+
+func NewCacheWithGlobPattern[K ~string, T any](opts ...Option[K, T]) (*WildcardCache[K, T], error) {
+	c := &WildcardCache[K, T]{
+		keys:    &trieNode{},
+		entries: make(map[K]*entry[K, T]),
+	}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.onSet = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.entries[e.key] = e
+			c.keys.insert(string(e.key))
+		}
+		o.onExit = func(e *entry[K, T]) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			// the key may have been stored again in the meantime
+			if c.entries[e.key] == e {
+				delete(c.entries, e.key)
+				c.keys.remove(string(e.key))
+			}
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	return c, nil
+}
+
+// KeysGlob returns the cached keys matching the pattern, it returns
+// path.ErrBadPattern for malformed patterns.
+func (c *WildcardCache[K, T]) KeysGlob(pattern string) ([]K, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// only keys starting with the literal prefix of the pattern can match
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []K
+	c.keys.walk(prefix, func(key string) {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, K(key))
+		}
+	})
+	return keys, nil
+}
+
+// DeleteGlob deletes the cached keys matching the pattern and returns them.
+func (c *WildcardCache[K, T]) DeleteGlob(pattern string) ([]K, error) {
+	keys, err := c.KeysGlob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		c.Delete(key)
+	}
+	return keys, nil
+}
+
+func (n *trieNode) insert(key string) {
+	for i := 0; i < len(key); i++ {
+		if n.children == nil {
+			n.children = make(map[byte]*trieNode)
+		}
+		child, ok := n.children[key[i]]
+		if !ok {
+			child = &trieNode{}
+			n.children[key[i]] = child
+		}
+		n = child
+	}
+	n.leaf = true
+}
+
+// remove removes the key and prunes the nodes no other key goes through.
+func (n *trieNode) remove(key string) {
+	if key == "" {
+		n.leaf = false
+		return
+	}
+	child, ok := n.children[key[0]]
+	if !ok {
+		return
+	}
+	child.remove(key[1:])
+	if !child.leaf && len(child.children) == 0 {
+		delete(n.children, key[0])
+	}
+}
+
+// walk calls fn for all keys starting with prefix.
+func (n *trieNode) walk(prefix string, fn func(key string)) {
+	for i := 0; i < len(prefix); i++ {
+		child, ok := n.children[prefix[i]]
+		if !ok {
+			return
+		}
+		n = child
+	}
+	n.collect([]byte(prefix), fn)
+}
+
+func (n *trieNode) collect(key []byte, fn func(key string)) {
+	if n.leaf {
+		fn(string(key))
+	}
+	for b, child := range n.children {
+		child.collect(append(key, b), fn)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// WindowLFUSegment is the part of a WindowLFUCache holding an entry.
+type WindowLFUSegment int
+
+const (
+	// WindowSegment holds new entries in LRU order.
+	WindowSegment WindowLFUSegment = iota + 1
+	// ProbationSegment holds entries admitted from the window which have not
+	// been hit since.
+	ProbationSegment
+	// ProtectedSegment holds entries hit while on probation.
+	ProtectedSegment
+)
+
+// protectedShare is the share of the main area reserved for protected entries.
+const protectedShare = 0.8
+
+// WindowLFUCache implements W-TinyLFU like ristretto, but with inspectable
+// segments and a configurable window. New entries go to a small LRU window.
+// Entries leaving the window are admitted to the probation segment if their
+// key was accessed more often than the probation entry they would evict,
+// and probation entries are promoted to the protected segment when hit.
+// Access frequencies are estimated with a count-min sketch which is reset
+// after ten accesses per entry of capacity. Entries expire after the default
+// TTL like in the other caches.
+type WindowLFUCache[K comparable, T any] struct {
+	windowSize    int
+	protectedSize int
+	mainSize      int
+
+	mu        sync.Mutex
+	window    *list.List
+	probation *list.List
+	protected *list.List
+	entries   map[K]*list.Element
+	sketch    *countMinSketch
+	accesses  int
+
+	locks sync.Map
+}
+
+type windowLFUEntry[K comparable, T any] struct {
+	key     K
+	segment WindowLFUSegment
+	itemValue[T]
+}
+
+// NewCacheWithHybridEviction returns a cache holding up to capacity entries,
+// windowFraction of them in the window, 1% if windowFraction is not positive.
+func NewCacheWithHybridEviction[K comparable, T any](capacity int, windowFraction float64) *WindowLFUCache[K, T] {
+	if windowFraction <= 0 {
+		windowFraction = 0.01
+	}
+	windowSize := max(1, int(float64(capacity)*windowFraction))
+	mainSize := max(1, capacity-windowSize)
+	return &WindowLFUCache[K, T]{
+		windowSize:    windowSize,
+		protectedSize: int(float64(mainSize) * protectedShare),
+		mainSize:      mainSize,
+		window:        list.New(),
+		probation:     list.New(),
+		protected:     list.New(),
+		entries:       make(map[K]*list.Element),
+		sketch:        newCountMinSketch(sketchDepth, sketchWidth),
+	}
+}
+
+func (c *WindowLFUCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	if value, ok := c.Peek(key); ok {
+		return value
+	}
+
+	anyLock, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	lock := anyLock.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// make sure the value has not been set while waiting for the lock
+	if value, ok := c.get(key); ok {
+		return value
+	}
+
+	value := read()
+	c.SetDefault(key, value)
+	return value
+}
+
+// Peek returns the cached value and counts the access.
+func (c *WindowLFUCache[K, T]) Peek(key K) (T, bool) {
+	c.mu.Lock()
+	c.record(key)
+	c.mu.Unlock()
+
+	return c.get(key)
+}
+
+// SetDefault stores the value with the default TTL. Updated entries count as
+// hit, new entries may evict an entry leaving the window.
+func (c *WindowLFUCache[K, T]) SetDefault(key K, value T) bool {
+	now := time.Now()
+	e := &windowLFUEntry[K, T]{key: key, itemValue: itemValue[T]{value: value, computedAt: now, expiresAt: now.Add(ttl)}}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		e.segment = elem.Value.(*windowLFUEntry[K, T]).segment
+		elem.Value = e
+		c.hit(elem)
+		return true
+	}
+	e.segment = WindowSegment
+	c.entries[key] = c.window.PushFront(e)
+	if c.window.Len() > c.windowSize {
+		c.admit(c.window.Back())
+	}
+	return true
+}
+
+func (c *WindowLFUCache[K, T]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Segment returns the segment holding the key.
+func (c *WindowLFUCache[K, T]) Segment(key K) (WindowLFUSegment, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	return elem.Value.(*windowLFUEntry[K, T]).segment, true
+}
+
+// Lens returns the number of entries in each segment including expired ones
+// which have not been removed yet.
+func (c *WindowLFUCache[K, T]) Lens() (window, probation, protected int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.window.Len(), c.probation.Len(), c.protected.Len()
+}
+
+func (c *WindowLFUCache[K, T]) get(key K) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	e := elem.Value.(*windowLFUEntry[K, T])
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(elem)
+		var zero T
+		return zero, false
+	}
+	c.hit(elem)
+	return e.value, true
+}
+
+func (c *WindowLFUCache[K, T]) record(key K) {
+	c.sketch.increment(key)
+	if c.accesses++; c.accesses >= 10*(c.windowSize+c.mainSize) {
+		c.sketch.reset()
+		c.accesses = 0
+	}
+}
+
+// hit moves the entry to the front of its segment, promoting probation
+// entries to the protected segment.
+func (c *WindowLFUCache[K, T]) hit(elem *list.Element) {
+	e := elem.Value.(*windowLFUEntry[K, T])
+	switch e.segment {
+	case WindowSegment:
+		c.window.MoveToFront(elem)
+	case ProtectedSegment:
+		c.protected.MoveToFront(elem)
+	case ProbationSegment:
+		c.probation.Remove(elem)
+		e.segment = ProtectedSegment
+		c.entries[e.key] = c.protected.PushFront(e)
+		if c.protected.Len() > c.protectedSize {
+			// demote the least recently used protected entry
+			demoted := c.protected.Remove(c.protected.Back()).(*windowLFUEntry[K, T])
+			demoted.segment = ProbationSegment
+			c.entries[demoted.key] = c.probation.PushFront(demoted)
+		}
+	}
+}
+
+// admit moves the entry leaving the window to the probation segment, if the
+// main area is full it either evicts the probation victim or the entry
+// itself, whichever was accessed less often.
+func (c *WindowLFUCache[K, T]) admit(elem *list.Element) {
+	candidate := c.window.Remove(elem).(*windowLFUEntry[K, T])
+	delete(c.entries, candidate.key)
+
+	if c.probation.Len()+c.protected.Len() >= c.mainSize {
+		victim := c.probation.Back()
+		if victim == nil {
+			victim = c.protected.Back()
+		}
+		if c.sketch.estimate(candidate.key) <= c.sketch.estimate(victim.Value.(*windowLFUEntry[K, T]).key) {
+			return
+		}
+		c.remove(victim)
+	}
+	candidate.segment = ProbationSegment
+	c.entries[candidate.key] = c.probation.PushFront(candidate)
+}
+
+func (c *WindowLFUCache[K, T]) remove(elem *list.Element) {
+	e := elem.Value.(*windowLFUEntry[K, T])
+	switch e.segment {
+	case WindowSegment:
+		c.window.Remove(elem)
+	case ProbationSegment:
+		c.probation.Remove(elem)
+	case ProtectedSegment:
+		c.protected.Remove(elem)
+	}
+	delete(c.entries, e.key)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncWriter persists values to the backing store of a WriteBehindCache.
+type AsyncWriter[K comparable, T any] interface {
+	Write(key K, value T) error
+}
+
+// WithAsyncWriter sets the writer of WriteBehindCache, values are written in
+// batches once batchSize values are pending or every flushInterval.
+func WithAsyncWriter[K comparable, T any](w AsyncWriter[K, T], batchSize int, flushInterval time.Duration) Option[K, T] {
+	return func(o *options[K, T]) {
+		o.asyncWriter = w
+		o.writeBatchSize = batchSize
+		o.writeInterval = flushInterval
+	}
+}
+
+// WriteBehindCache writes values read into the cache or set with SetDefault
+// to the async writer without waiting for it. Failed writes are counted and
+// retried with the next batch. Values not written yet are included in
+// snapshots.
+type WriteBehindCache[K comparable, T any] struct {
+	*LZ4SnapshotCache[K, T]
+	errors atomic.Uint64
+
+	mu sync.Mutex
+	// pending holds the latest unwritten value of each key
+	pending map[K]T
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewCacheWithAsyncWriter[K comparable, T any](writer AsyncWriter[K, T], opts ...Option[K, T]) (*WriteBehindCache[K, T], error) {
+	opts = append([]Option[K, T]{WithAsyncWriter(writer, 100, time.Second)}, opts...)
+	cache, err := NewCacheWithLZ4Snapshot(opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := &WriteBehindCache[K, T]{
+		LZ4SnapshotCache: cache,
+		pending:          make(map[K]T),
+		flush:            make(chan struct{}, 1),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+	go c.run()
+	return c, nil
+}
+
+func (c *WriteBehindCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	value, _ := c.LoadOrStoreE(key, read.withError())
+	return value
+}
+
+func (c *WriteBehindCache[K, T]) LoadOrStoreE(key K, read readerE[T]) (T, error) {
+	value, _, err := c.loadOrStoreSource(key, func() (T, error) {
+		value, err := read()
+		if err == nil {
+			c.enqueue(key, value)
+		}
+		return value, err
+	}, read)
+	return value, err
+}
+
+func (c *WriteBehindCache[K, T]) SetDefault(key K, value T) bool {
+	c.enqueue(key, value)
+	return c.ristrettoCache.SetDefault(key, value)
+}
+
+// Snapshot writes the cached entries and the values not written yet to w.
+func (c *WriteBehindCache[K, T]) Snapshot(w io.Writer) (SnapshotStats, error) {
+	c.mu.Lock()
+	extra := make([]snapshotRecord[K, T], 0, len(c.pending))
+	expiresAt := time.Now().Add(c.defaultTTL)
+	for key, value := range c.pending {
+		extra = append(extra, snapshotRecord[K, T]{Key: key, Value: value, ExpiresAt: expiresAt})
+	}
+	c.mu.Unlock()
+
+	return c.snapshot(w, extra, nil)
+}
+
+func (c *WriteBehindCache[K, T]) Stats() Stats {
+	stats := c.ristrettoCache.Stats()
+	stats.WriteErrors = c.errors.Load()
+	return stats
+}
+
+// Close writes the pending values and closes the cache.
+func (c *WriteBehindCache[K, T]) Close() {
+	close(c.stop)
+	<-c.done
+	c.ristrettoCache.Close()
+}
+
+func (c *WriteBehindCache[K, T]) enqueue(key K, value T) {
+	c.mu.Lock()
+	c.pending[key] = value
+	full := len(c.pending) >= c.writeBatchSize
+	c.mu.Unlock()
+
+	if full {
+		select {
+		case c.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (c *WriteBehindCache[K, T]) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.writeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.flush:
+		case <-c.stop:
+			c.write()
+			return
+		}
+		c.write()
+	}
+}
+
+func (c *WriteBehindCache[K, T]) write() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = make(map[K]T, len(batch))
+	c.mu.Unlock()
+
+	for key, value := range batch {
+		if err := c.asyncWriter.Write(key, value); err != nil {
+			c.errors.Add(1)
+			c.mu.Lock()
+			// keep newer values stored meanwhile
+			if _, ok := c.pending[key]; !ok {
+				c.pending[key] = value
+			}
+			c.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+type bufferedWrite[K comparable, T any] struct {
+	e    *entry[K, T]
+	done chan struct{}
+}
+
+// WriteBufferedCache stores the values read on misses through a single
+// goroutine, which applies all queued writes and then waits for ristretto
+// once for the whole batch instead of once per write.
+type WriteBufferedCache[K comparable, T any] struct {
+	*ristrettoCache[K, T]
+	writes chan bufferedWrite[K, T]
+}
+
+func NewCacheWithWriteBuffer[K comparable, T any](bufSize int, opts ...Option[K, T]) (*WriteBufferedCache[K, T], error) {
+	c := &WriteBufferedCache[K, T]{writes: make(chan bufferedWrite[K, T], bufSize)}
+	cache, err := NewCache(append(opts, func(o *options[K, T]) {
+		o.storeMiss = func(e *entry[K, T]) error {
+			done := make(chan struct{})
+			c.writes <- bufferedWrite[K, T]{e: e, done: done}
+			<-done
+			return nil
+		}
+	})...)
+	if err != nil {
+		return nil, err
+	}
+	c.ristrettoCache = cache
+	go c.write()
+	return c, nil
+}
+
+// Close stops the writer goroutine and closes the cache, it must not be
+// called while LoadOrStore calls are in progress.
+func (c *WriteBufferedCache[K, T]) Close() {
+	close(c.writes)
+	c.ristrettoCache.Close()
+}
+
+func (c *WriteBufferedCache[K, T]) write() {
+	var batch []bufferedWrite[K, T]
+	for w := range c.writes {
+		batch = append(batch[:0], w)
+		// take all writes which are already queued
+	drain:
+		for {
+			select {
+			case w, ok := <-c.writes:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, w)
+			default:
+				break drain
+			}
+		}
+
+		for _, w := range batch {
+			c.setEntry(w.e)
+		}
+		c.cache.Wait()
+		for _, w := range batch {
+			close(w.done)
+		}
+	}
+}
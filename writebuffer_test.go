@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWriteBufferStoresMisses(t *testing.T) {
+	errOdd := errors.New("odd")
+	c, err := NewCacheWithWriteBuffer(16, WithValueValidator[string, int](func(value int) error {
+		if value%2 != 0 {
+			return errOdd
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.LoadOrStore(strconv.Itoa(i), func() int { return i })
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 100; i++ {
+		_, ok := c.Peek(strconv.Itoa(i))
+		if want := i%2 == 0; ok != want {
+			t.Errorf("key %d cached: %v, want %v", i, ok, want)
+		}
+	}
+}
+
+// BenchmarkMissStores stores a new key on every call.
+func BenchmarkMissStores(b *testing.B) {
+	run := func(b *testing.B, c Cache[int, int]) {
+		var next atomic.Int64
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				key := int(next.Add(1))
+				c.LoadOrStore(key, func() int { return key })
+			}
+		})
+	}
+	b.Run("ristretto", func(b *testing.B) {
+		c, err := NewCache[int, int]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		run(b, c)
+	})
+	b.Run("write-buffer", func(b *testing.B) {
+		c, err := NewCacheWithWriteBuffer[int, int](1024)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer c.Close()
+		run(b, c)
+	})
+}
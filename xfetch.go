@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+type xfetchValue[T any] struct {
+	value     T
+	delta     time.Duration
+	expiresAt time.Time
+}
+
+// ProbabilisticCache refreshes entries before they expire using the XFetch
+// algorithm (Vattani et al.), which prevents stampedes of readers when a
+// popular entry expires. The probability of an early refresh grows as the
+// entry approaches its expiry and with the time the reader took; beta scales
+// it, 1 is a good default.
+type ProbabilisticCache[K comparable, T any] struct {
+	cache *ristrettoCache[K, xfetchValue[T]]
+	beta  float64
+}
+
+func NewProbabilisticEarlyExpiry[K comparable, T any](beta float64) (*ProbabilisticCache[K, T], error) {
+	cache, err := NewCache[K, xfetchValue[T]]()
+	if err != nil {
+		return nil, err
+	}
+	return &ProbabilisticCache[K, T]{
+		cache: cache,
+		beta:  beta,
+	}, nil
+}
+
+func (c *ProbabilisticCache[K, T]) LoadOrStore(key K, read reader[T]) T {
+	v, ok := c.cache.Peek(key)
+	if !ok {
+		return c.cache.LoadOrStore(key, c.timed(read)).value
+	}
+
+	early := time.Duration(float64(v.delta) * c.beta * -math.Log(1-rand.Float64()))
+	if time.Now().Add(early).Before(v.expiresAt) {
+		return v.value
+	}
+
+	// refresh the entry early, unless another goroutine is already doing it
+	lock := c.cache.keyLock(key)
+	if !lock.TryLock() {
+		return v.value
+	}
+	defer lock.Unlock()
+
+	v = c.timed(read)()
+	c.cache.SetDefault(key, v)
+	return v.value
+}
+
+func (c *ProbabilisticCache[K, T]) Close() {
+	c.cache.Close()
+}
+
+func (c *ProbabilisticCache[K, T]) timed(read reader[T]) reader[xfetchValue[T]] {
+	return func() xfetchValue[T] {
+		start := time.Now()
+		value := read()
+		now := time.Now()
+		return xfetchValue[T]{
+			value:     value,
+			delta:     now.Sub(start),
+			expiresAt: now.Add(c.cache.defaultTTL),
+		}
+	}
+}